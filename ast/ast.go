@@ -43,10 +43,13 @@ func (p *Program) String() string {
 }
 
 // let <identifier> = <expression>;
+// const <identifier>: <type> = <expression>;
 type LetStatement struct {
 	Token token.Token // what node ast refering to
 	Name  *Identifier
+	Type  *TypeAnnotation // optional
 	Value Expression
+	Const bool
 }
 
 func (ls *LetStatement) statementNode()       {}
@@ -55,6 +58,9 @@ func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(ls.TokenLiteral() + " ")
 	out.WriteString(ls.Name.String())
+	if ls.Type != nil {
+		out.WriteString(": " + ls.Type.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -65,6 +71,40 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+// <name>, <name>[<type>, ...] or cook(<type>, ...): <type>
+// only read by the checker, eval ignores them
+type TypeAnnotation struct {
+	Token  token.Token
+	Name   string
+	Params []*TypeAnnotation // element types or cook parameters
+	Return *TypeAnnotation   // optional, only for cook
+}
+
+func (ta *TypeAnnotation) TokenLiteral() string { return ta.Token.Literal }
+func (ta *TypeAnnotation) String() string {
+	var out bytes.Buffer
+	out.WriteString(ta.Name)
+
+	params := []string{}
+	for _, p := range ta.Params {
+		params = append(params, p.String())
+	}
+
+	if ta.Token.Type == token.FUNC {
+		// bare cook is any function
+		if ta.Params == nil && ta.Return == nil {
+			return out.String()
+		}
+		out.WriteString("(" + strings.Join(params, ", ") + ")")
+		if ta.Return != nil {
+			out.WriteString(": " + ta.Return.String())
+		}
+	} else if len(params) > 0 {
+		out.WriteString("[" + strings.Join(params, ", ") + "]")
+	}
+	return out.String()
+}
+
 type Identifier struct {
 	Token token.Token
 	Value string
@@ -221,52 +261,187 @@ func (bs *BlockStatement) String() string {
 // func (<arguments>) <body>;
 type FunctionLiteral struct {
 	Token      token.Token
+	Name       string // set for class methods
 	Parameters []*Identifier
+	Defaults   map[string]Expression      // default values by parameter name
+	Rest       *Identifier                // trailing ...rest parameter
+	Types      map[string]*TypeAnnotation // optional, by parameter name
+	Return     *TypeAnnotation            // optional
 	Body       *BlockStatement
+	Generator  bool // body contains slay
+	Async      bool // declared with lowkey
+	Arrow      bool // cook(x) => x, body is a single rizz
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
+	if fl.Async {
+		out.WriteString("lowkey ")
+	}
 	out.WriteString(fl.TokenLiteral())
+	if fl.Name != "" {
+		out.WriteString(" " + fl.Name)
+	}
 
 	params := []string{}
 	for _, p := range fl.Parameters {
-		params = append(params, p.String())
+		param := p.String()
+		if typ, ok := fl.Types[p.Value]; ok {
+			param += ": " + typ.String()
+		}
+		if def, ok := fl.Defaults[p.Value]; ok {
+			param += " = " + def.String()
+		}
+		params = append(params, param)
+	}
+	if fl.Rest != nil {
+		param := "..." + fl.Rest.String()
+		if typ, ok := fl.Types[fl.Rest.Value]; ok {
+			param += ": " + typ.String()
+		}
+		params = append(params, param)
 	}
 
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ","))
 	out.WriteString(")")
+	if fl.Return != nil {
+		out.WriteString(": " + fl.Return.String())
+	}
+	if fl.Arrow {
+		ret := fl.Body.Statements[0].(*ReturnStatement)
+		out.WriteString(" => ")
+		out.WriteString(ret.Value.String())
+		return out.String()
+	}
 	out.WriteString(fl.Body.String())
 	return out.String()
 }
 
+// macro (<arguments>) <body>;
+type MacroLiteral struct {
+	Token      token.Token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ml *MacroLiteral) expressionNode()      {}
+func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MacroLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range ml.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(ml.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ","))
+	out.WriteString(")")
+	out.WriteString(ml.Body.String())
+	return out.String()
+}
+
+// await <expression>
+type AwaitExpression struct {
+	Token token.Token
+	Value Expression
+}
+
+func (ae *AwaitExpression) expressionNode()      {}
+func (ae *AwaitExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AwaitExpression) String() string {
+	return "(" + ae.TokenLiteral() + " " + ae.Value.String() + ")"
+}
+
+// spawn <call expression>
+type SpawnExpression struct {
+	Token token.Token
+	Call  *CallExpression
+}
+
+func (se *SpawnExpression) expressionNode()      {}
+func (se *SpawnExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpawnExpression) String() string {
+	return se.TokenLiteral() + " " + se.Call.String()
+}
+
+// yield <expression>
+type YieldExpression struct {
+	Token token.Token
+	Value Expression
+}
+
+func (ye *YieldExpression) expressionNode()      {}
+func (ye *YieldExpression) TokenLiteral() string { return ye.Token.Literal }
+func (ye *YieldExpression) String() string {
+	return ye.TokenLiteral() + " " + ye.Value.String()
+}
+
 // <expression>(<comma seperated expressions>);
 type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	Piped     bool // x |> f(a), the first argument came from the left
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
-	out.WriteString(ce.TokenLiteral())
 
 	args := []string{}
 	for _, a := range ce.Arguments {
 		args = append(args, a.String())
 	}
 
+	if ce.Piped {
+		out.WriteString("(")
+		out.WriteString(args[0])
+		out.WriteString(" |> ")
+		out.WriteString(ce.Function.String())
+		// x |> f keeps the pipe as its token and has no parens
+		if ce.Token.Type != token.PIPE {
+			out.WriteString("(")
+			out.WriteString(strings.Join(args[1:], ", "))
+			out.WriteString(")")
+		}
+		out.WriteString(")")
+		return out.String()
+	}
+
+	out.WriteString(ce.Function.String())
 	out.WriteString("(")
-	out.WriteString(strings.Join(args, ","))
+	out.WriteString(strings.Join(args, ", "))
 	out.WriteString(")")
 	return out.String()
 }
 
+// ...<expression>
+type SpreadExpression struct {
+	Token token.Token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) String() string       { return "..." + se.Value.String() }
+
+// <identifier> = <expression> inside call arguments
+type NamedArgument struct {
+	Token token.Token
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode()      {}
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Literal }
+func (na *NamedArgument) String() string       { return na.Name.String() + " = " + na.Value.String() }
+
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
@@ -290,9 +465,10 @@ func (al *ArrayLiteral) String() string {
 
 // <expression>[<expression>]
 type IndexExpression struct {
-	Token token.Token
-	Left  Expression
-	Index Expression
+	Token    token.Token
+	Left     Expression
+	Index    Expression
+	Optional bool // x?.[y]
 }
 
 func (ie *IndexExpression) expressionNode()      {}
@@ -302,15 +478,55 @@ func (ie *IndexExpression) String() string {
 
 	out.WriteString("(")
 	out.WriteString(ie.Left.String())
+	if ie.Optional {
+		out.WriteString("?.")
+	}
 	out.WriteString("[")
 	out.WriteString(ie.Index.String())
 	out.WriteString("])")
 	return out.String()
 }
 
+// <expression>[<start>:<end>:<step>] with every part optional
+type SliceExpression struct {
+	Token    token.Token
+	Left     Expression
+	Start    Expression
+	End      Expression
+	Step     Expression
+	Optional bool // x?.[a:b]
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	if se.Optional {
+		out.WriteString("?.")
+	}
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	if se.Step != nil {
+		out.WriteString(":")
+		out.WriteString(se.Step.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
 // {<expression> : <expression>}
 type MapLiteral struct {
 	Token token.Token
+	Keys  []Expression // keys in source order
 	Pairs map[Expression]Expression
 }
 
@@ -320,8 +536,8 @@ func (ml *MapLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for key, val := range ml.Pairs {
-		pairs = append(pairs, key.String()+": "+val.String())
+	for _, key := range ml.Keys {
+		pairs = append(pairs, key.String()+": "+ml.Pairs[key].String())
 	}
 
 	out.WriteString("{")
@@ -330,6 +546,67 @@ func (ml *MapLiteral) String() string {
 	return out.String()
 }
 
+// {<expression>, <expression>, ...}
+type SetLiteral struct {
+	Token    token.Token
+	Elements []Expression
+}
+
+func (sl *SetLiteral) expressionNode()      {}
+func (sl *SetLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *SetLiteral) String() string {
+	var out bytes.Buffer
+
+	elems := []string{}
+	for _, elem := range sl.Elements {
+		elems = append(elems, elem.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(elems, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// [<expression> mew <identifier> in <expression> hawk <expression>]
+// or the same in braces for sets and {<key>: <value> mew ...} for maps
+type Comprehension struct {
+	Token     token.Token // [ or {
+	Key       Expression  // only set for maps
+	Value     Expression
+	Variable  *Identifier
+	Iterable  Expression
+	Condition Expression // optional
+}
+
+func (c *Comprehension) expressionNode()      {}
+func (c *Comprehension) TokenLiteral() string { return c.Token.Literal }
+func (c *Comprehension) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(c.TokenLiteral())
+	if c.Key != nil {
+		out.WriteString(c.Key.String())
+		out.WriteString(": ")
+	}
+	out.WriteString(c.Value.String())
+	out.WriteString(" mew ")
+	out.WriteString(c.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(c.Iterable.String())
+	if c.Condition != nil {
+		out.WriteString(" hawk ")
+		out.WriteString(c.Condition.String())
+	}
+
+	if c.Token.Type == token.LBRACKET {
+		out.WriteString("]")
+	} else {
+		out.WriteString("}")
+	}
+	return out.String()
+}
+
 // for <expression> <body>
 type ForExpression struct {
 	Token     token.Token
@@ -349,3 +626,150 @@ func (fl *ForExpression) String() string {
 	out.WriteString("}")
 	return out.String()
 }
+
+// for (<identifier> in <expression>) <body>
+type ForEachExpression struct {
+	Token    token.Token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fe *ForEachExpression) expressionNode()      {}
+func (fe *ForEachExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForEachExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString(fe.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(fe.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fe.Iterable.String())
+	out.WriteString("){")
+	out.WriteString(fe.Body.String())
+	out.WriteString("}")
+	return out.String()
+}
+
+// record <identifier> { <identifier>, <identifier> };
+type RecordStatement struct {
+	Token  token.Token
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+func (rs *RecordStatement) statementNode()       {}
+func (rs *RecordStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *RecordStatement) String() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, f := range rs.Fields {
+		fields = append(fields, f.String())
+	}
+
+	out.WriteString(rs.TokenLiteral() + " ")
+	out.WriteString(rs.Name.String())
+	out.WriteString(" {")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("};")
+	return out.String()
+}
+
+// <expression>.<identifier>
+type MemberExpression struct {
+	Token    token.Token
+	Left     Expression
+	Property *Identifier
+	Optional bool // x?.y
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	return "(" + me.Left.String() + me.TokenLiteral() + me.Property.String() + ")"
+}
+
+// <expression> = <expression>;
+type AssignStatement struct {
+	Token  token.Token
+	Target Expression
+	Value  Expression
+}
+
+func (as *AssignStatement) statementNode()       {}
+func (as *AssignStatement) TokenLiteral() string { return as.Token.Literal }
+func (as *AssignStatement) String() string {
+	return as.Target.String() + " = " + as.Value.String() + ";"
+}
+
+// class <identifier> < <parent> { <methods> }
+type ClassStatement struct {
+	Token   token.Token
+	Name    *Identifier
+	Parent  *Identifier
+	Methods []*FunctionLiteral
+}
+
+func (cs *ClassStatement) statementNode()       {}
+func (cs *ClassStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ClassStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(cs.TokenLiteral() + " ")
+	out.WriteString(cs.Name.String())
+	if cs.Parent != nil {
+		out.WriteString(" < " + cs.Parent.String())
+	}
+
+	out.WriteString(" {")
+	for _, m := range cs.Methods {
+		out.WriteString(m.String())
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+// throw <expression>;
+type ThrowStatement struct {
+	Token token.Token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) String() string {
+	return ts.TokenLiteral() + " " + ts.Value.String() + ";"
+}
+
+// try <block> catch (<identifier>) <block> finally <block>
+type TryExpression struct {
+	Token   token.Token
+	Block   *BlockStatement
+	Param   *Identifier // optional
+	Catch   *BlockStatement
+	Finally *BlockStatement
+}
+
+func (te *TryExpression) expressionNode()      {}
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString(te.TokenLiteral())
+	out.WriteString("{")
+	out.WriteString(te.Block.String())
+	out.WriteString("}")
+
+	if te.Catch != nil {
+		out.WriteString("cope")
+		if te.Param != nil {
+			out.WriteString("(" + te.Param.String() + ")")
+		}
+		out.WriteString("{" + te.Catch.String() + "}")
+	}
+	if te.Finally != nil {
+		out.WriteString("periodt")
+		out.WriteString("{" + te.Finally.String() + "}")
+	}
+
+	return out.String()
+}
@@ -0,0 +1,246 @@
+package ast
+
+// NOTE:
+// Modify rewrites nodes in place, so code that is
+// modified more than once (quote, macro bodies) works
+// on a copy. type annotations are never modified and
+// stay shared
+func Copy(node Node) Node {
+	switch node := node.(type) {
+	case *Program:
+		c := *node
+		c.Statements = copyStatements(node.Statements)
+		return &c
+	case *BlockStatement:
+		c := *node
+		c.Statements = copyStatements(node.Statements)
+		return &c
+	case *ExpressionStatement:
+		c := *node
+		c.Expression = copyExpression(node.Expression)
+		return &c
+	case *LetStatement:
+		c := *node
+		c.Name = copyIdentifier(node.Name)
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *ReturnStatement:
+		c := *node
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *AssignStatement:
+		c := *node
+		c.Target = copyExpression(node.Target)
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *ThrowStatement:
+		c := *node
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *RecordStatement:
+		c := *node
+		c.Name = copyIdentifier(node.Name)
+		c.Fields = copyIdentifiers(node.Fields)
+		return &c
+	case *ClassStatement:
+		c := *node
+		c.Name = copyIdentifier(node.Name)
+		c.Parent = copyIdentifier(node.Parent)
+		c.Methods = nil
+		for _, m := range node.Methods {
+			c.Methods = append(c.Methods, Copy(m).(*FunctionLiteral))
+		}
+		return &c
+	case *Identifier:
+		c := *node
+		return &c
+	case *IntegerLiteral:
+		c := *node
+		return &c
+	case *StringLiteral:
+		c := *node
+		return &c
+	case *Boolean:
+		c := *node
+		return &c
+	case *PrefixExpression:
+		c := *node
+		c.Right = copyExpression(node.Right)
+		return &c
+	case *InfixExpression:
+		c := *node
+		c.Left = copyExpression(node.Left)
+		c.Right = copyExpression(node.Right)
+		return &c
+	case *IfElseExpression:
+		c := *node
+		c.Predicate = copyExpression(node.Predicate)
+		c.Consequence = copyBlock(node.Consequence)
+		c.Alternative = copyBlock(node.Alternative)
+		return &c
+	case *FunctionLiteral:
+		c := *node
+		c.Parameters = copyIdentifiers(node.Parameters)
+		if node.Defaults != nil {
+			c.Defaults = make(map[string]Expression, len(node.Defaults))
+			for name, def := range node.Defaults {
+				c.Defaults[name] = copyExpression(def)
+			}
+		}
+		c.Rest = copyIdentifier(node.Rest)
+		c.Body = copyBlock(node.Body)
+		return &c
+	case *MacroLiteral:
+		c := *node
+		c.Parameters = copyIdentifiers(node.Parameters)
+		c.Body = copyBlock(node.Body)
+		return &c
+	case *YieldExpression:
+		c := *node
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *SpawnExpression:
+		c := *node
+		if node.Call != nil {
+			c.Call = Copy(node.Call).(*CallExpression)
+		}
+		return &c
+	case *AwaitExpression:
+		c := *node
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *CallExpression:
+		c := *node
+		c.Function = copyExpression(node.Function)
+		c.Arguments = copyExpressions(node.Arguments)
+		return &c
+	case *SpreadExpression:
+		c := *node
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *NamedArgument:
+		c := *node
+		c.Name = copyIdentifier(node.Name)
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *ArrayLiteral:
+		c := *node
+		c.Elements = copyExpressions(node.Elements)
+		return &c
+	case *SetLiteral:
+		c := *node
+		c.Elements = copyExpressions(node.Elements)
+		return &c
+	case *MapLiteral:
+		c := *node
+		c.Keys = nil
+		c.Pairs = make(map[Expression]Expression, len(node.Pairs))
+		for _, key := range node.Keys {
+			k := copyExpression(key)
+			c.Keys = append(c.Keys, k)
+			c.Pairs[k] = copyExpression(node.Pairs[key])
+		}
+		return &c
+	case *IndexExpression:
+		c := *node
+		c.Left = copyExpression(node.Left)
+		c.Index = copyExpression(node.Index)
+		return &c
+	case *SliceExpression:
+		c := *node
+		c.Left = copyExpression(node.Left)
+		c.Start = copyExpression(node.Start)
+		c.End = copyExpression(node.End)
+		c.Step = copyExpression(node.Step)
+		return &c
+	case *MemberExpression:
+		c := *node
+		c.Left = copyExpression(node.Left)
+		c.Property = copyIdentifier(node.Property)
+		return &c
+	case *ForExpression:
+		c := *node
+		c.Condition = copyExpression(node.Condition)
+		c.Body = copyBlock(node.Body)
+		return &c
+	case *Comprehension:
+		c := *node
+		c.Key = copyExpression(node.Key)
+		c.Value = copyExpression(node.Value)
+		c.Variable = copyIdentifier(node.Variable)
+		c.Iterable = copyExpression(node.Iterable)
+		c.Condition = copyExpression(node.Condition)
+		return &c
+	case *ForEachExpression:
+		c := *node
+		c.Variable = copyIdentifier(node.Variable)
+		c.Iterable = copyExpression(node.Iterable)
+		c.Body = copyBlock(node.Body)
+		return &c
+	case *TryExpression:
+		c := *node
+		c.Block = copyBlock(node.Block)
+		c.Param = copyIdentifier(node.Param)
+		c.Catch = copyBlock(node.Catch)
+		c.Finally = copyBlock(node.Finally)
+		return &c
+	}
+
+	return node
+}
+
+// missing optional children stay nil
+func copyExpression(exp Expression) Expression {
+	if exp == nil {
+		return nil
+	}
+	c, _ := Copy(exp).(Expression)
+	return c
+}
+
+func copyIdentifier(ident *Identifier) *Identifier {
+	if ident == nil {
+		return nil
+	}
+	return Copy(ident).(*Identifier)
+}
+
+func copyBlock(block *BlockStatement) *BlockStatement {
+	if block == nil {
+		return nil
+	}
+	return Copy(block).(*BlockStatement)
+}
+
+func copyStatements(stmts []Statement) []Statement {
+	if stmts == nil {
+		return nil
+	}
+	c := make([]Statement, len(stmts))
+	for i, stmt := range stmts {
+		c[i], _ = Copy(stmt).(Statement)
+	}
+	return c
+}
+
+func copyExpressions(exps []Expression) []Expression {
+	if exps == nil {
+		return nil
+	}
+	c := make([]Expression, len(exps))
+	for i, exp := range exps {
+		c[i] = copyExpression(exp)
+	}
+	return c
+}
+
+func copyIdentifiers(idents []*Identifier) []*Identifier {
+	if idents == nil {
+		return nil
+	}
+	c := make([]*Identifier, len(idents))
+	for i, ident := range idents {
+		c[i] = copyIdentifier(ident)
+	}
+	return c
+}
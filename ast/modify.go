@@ -0,0 +1,169 @@
+package ast
+
+type ModifierFunc func(Node) Node
+
+// NOTE:
+// children are modified before their parent so the
+// modifier sees nodes that were already rewritten.
+// optional children that are missing stay nil
+func Modify(node Node, modifier ModifierFunc) Node {
+	switch node := node.(type) {
+	case *Program:
+		modifyStatements(node.Statements, modifier)
+	case *BlockStatement:
+		modifyStatements(node.Statements, modifier)
+	case *ExpressionStatement:
+		node.Expression = modified(node.Expression, modifier)
+	case *LetStatement:
+		node.Name = modified(node.Name, modifier)
+		node.Value = modified(node.Value, modifier)
+	case *ReturnStatement:
+		if node.Value != nil {
+			node.Value = modified(node.Value, modifier)
+		}
+	case *AssignStatement:
+		node.Target = modified(node.Target, modifier)
+		node.Value = modified(node.Value, modifier)
+	case *ThrowStatement:
+		node.Value = modified(node.Value, modifier)
+	case *RecordStatement:
+		node.Name = modified(node.Name, modifier)
+		modifyIdentifiers(node.Fields, modifier)
+	case *ClassStatement:
+		node.Name = modified(node.Name, modifier)
+		if node.Parent != nil {
+			node.Parent = modified(node.Parent, modifier)
+		}
+		for i, m := range node.Methods {
+			node.Methods[i] = modified(m, modifier)
+		}
+	case *PrefixExpression:
+		node.Right = modified(node.Right, modifier)
+	case *InfixExpression:
+		node.Left = modified(node.Left, modifier)
+		node.Right = modified(node.Right, modifier)
+	case *IfElseExpression:
+		node.Predicate = modified(node.Predicate, modifier)
+		node.Consequence = modified(node.Consequence, modifier)
+		if node.Alternative != nil {
+			node.Alternative = modified(node.Alternative, modifier)
+		}
+	case *FunctionLiteral:
+		// defaults are keyed by name so they follow renamed parameters
+		defaults := make(map[string]Expression, len(node.Defaults))
+		for i, param := range node.Parameters {
+			def, ok := node.Defaults[param.Value]
+			node.Parameters[i] = modified(param, modifier)
+			if ok {
+				defaults[node.Parameters[i].Value] = modified(def, modifier)
+			}
+		}
+		if node.Defaults != nil {
+			node.Defaults = defaults
+		}
+		if node.Rest != nil {
+			node.Rest = modified(node.Rest, modifier)
+		}
+		node.Body = modified(node.Body, modifier)
+	case *MacroLiteral:
+		modifyIdentifiers(node.Parameters, modifier)
+		node.Body = modified(node.Body, modifier)
+	case *YieldExpression:
+		node.Value = modified(node.Value, modifier)
+	case *SpawnExpression:
+		node.Call = modified(node.Call, modifier)
+	case *AwaitExpression:
+		node.Value = modified(node.Value, modifier)
+	case *CallExpression:
+		node.Function = modified(node.Function, modifier)
+		modifyExpressions(node.Arguments, modifier)
+	case *SpreadExpression:
+		node.Value = modified(node.Value, modifier)
+	case *NamedArgument:
+		node.Name = modified(node.Name, modifier)
+		node.Value = modified(node.Value, modifier)
+	case *ArrayLiteral:
+		modifyExpressions(node.Elements, modifier)
+	case *SetLiteral:
+		modifyExpressions(node.Elements, modifier)
+	case *MapLiteral:
+		pairs := make(map[Expression]Expression)
+		for i, key := range node.Keys {
+			val := node.Pairs[key]
+			key := modified(key, modifier)
+			pairs[key] = modified(val, modifier)
+			node.Keys[i] = key
+		}
+		node.Pairs = pairs
+	case *IndexExpression:
+		node.Left = modified(node.Left, modifier)
+		node.Index = modified(node.Index, modifier)
+	case *SliceExpression:
+		node.Left = modified(node.Left, modifier)
+		for _, part := range []*Expression{&node.Start, &node.End, &node.Step} {
+			if *part != nil {
+				*part = modified(*part, modifier)
+			}
+		}
+	case *MemberExpression:
+		node.Left = modified(node.Left, modifier)
+		node.Property = modified(node.Property, modifier)
+	case *ForExpression:
+		node.Condition = modified(node.Condition, modifier)
+		node.Body = modified(node.Body, modifier)
+	case *Comprehension:
+		if node.Key != nil {
+			node.Key = modified(node.Key, modifier)
+		}
+		node.Value = modified(node.Value, modifier)
+		node.Variable = modified(node.Variable, modifier)
+		node.Iterable = modified(node.Iterable, modifier)
+		if node.Condition != nil {
+			node.Condition = modified(node.Condition, modifier)
+		}
+	case *ForEachExpression:
+		node.Variable = modified(node.Variable, modifier)
+		node.Iterable = modified(node.Iterable, modifier)
+		node.Body = modified(node.Body, modifier)
+	case *TryExpression:
+		node.Block = modified(node.Block, modifier)
+		if node.Param != nil {
+			node.Param = modified(node.Param, modifier)
+		}
+		if node.Catch != nil {
+			node.Catch = modified(node.Catch, modifier)
+		}
+		if node.Finally != nil {
+			node.Finally = modified(node.Finally, modifier)
+		}
+	}
+
+	return modifier(node)
+}
+
+// keep the old node when the modifier returns a missing
+// or wrong kind of node, so no field ends up nil
+func modified[T Node](node T, modifier ModifierFunc) T {
+	if res, ok := Modify(node, modifier).(T); ok {
+		return res
+	}
+	return node
+}
+
+func modifyStatements(stmts []Statement, modifier ModifierFunc) {
+	for i, stmt := range stmts {
+		stmts[i] = modified(stmt, modifier)
+	}
+}
+
+func modifyExpressions(exps []Expression, modifier ModifierFunc) {
+	for i, exp := range exps {
+		exps[i] = modified(exp, modifier)
+	}
+}
+
+func modifyIdentifiers(idents []*Identifier, modifier ModifierFunc) {
+	for i, ident := range idents {
+		idents[i] = modified(ident, modifier)
+	}
+}
@@ -0,0 +1,94 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestModify(t *testing.T) {
+	one := func() Expression { return &IntegerLiteral{Value: 1} }
+	two := func() Expression { return &IntegerLiteral{Value: 2} }
+
+	turnOneIntoTwo := func(node Node) Node {
+		integer, ok := node.(*IntegerLiteral)
+		if !ok || integer.Value != 1 {
+			return node
+		}
+		integer.Value = 2
+		return integer
+	}
+
+	tests := []struct {
+		got  Node
+		want Node
+	}{
+		{one(), two()},
+		{
+			&Program{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			&Program{Statements: []Statement{&ExpressionStatement{Expression: two()}}},
+		},
+		{&InfixExpression{Left: one(), Operator: "+", Right: two()}, &InfixExpression{Left: two(), Operator: "+", Right: two()}},
+		{&InfixExpression{Left: two(), Operator: "+", Right: one()}, &InfixExpression{Left: two(), Operator: "+", Right: two()}},
+		{&PrefixExpression{Operator: "-", Right: one()}, &PrefixExpression{Operator: "-", Right: two()}},
+		{&IndexExpression{Left: one(), Index: one()}, &IndexExpression{Left: two(), Index: two()}},
+		{
+			&IfElseExpression{
+				Predicate:   one(),
+				Consequence: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+				Alternative: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			},
+			&IfElseExpression{
+				Predicate:   two(),
+				Consequence: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: two()}}},
+				Alternative: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: two()}}},
+			},
+		},
+		{&ReturnStatement{Value: one()}, &ReturnStatement{Value: two()}},
+		{&LetStatement{Value: one()}, &LetStatement{Value: two()}},
+		{
+			&FunctionLiteral{Parameters: []*Identifier{}, Body: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}}},
+			&FunctionLiteral{Parameters: []*Identifier{}, Body: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: two()}}}},
+		},
+		{&CallExpression{Function: one(), Arguments: []Expression{one()}}, &CallExpression{Function: two(), Arguments: []Expression{two()}}},
+		{&ArrayLiteral{Elements: []Expression{one(), one()}}, &ArrayLiteral{Elements: []Expression{two(), two()}}},
+	}
+
+	for _, tt := range tests {
+		modified := Modify(tt.got, turnOneIntoTwo)
+		if !reflect.DeepEqual(modified, tt.want) {
+			t.Errorf("modified not equal to %#v: got=%#v", tt.want, modified)
+		}
+	}
+
+	mp := &MapLiteral{Keys: []Expression{one(), one()}, Pairs: map[Expression]Expression{}}
+	mp.Pairs[mp.Keys[0]] = one()
+	mp.Pairs[mp.Keys[1]] = one()
+	Modify(mp, turnOneIntoTwo)
+	for _, key := range mp.Keys {
+		if key.(*IntegerLiteral).Value != 2 || mp.Pairs[key].(*IntegerLiteral).Value != 2 {
+			t.Errorf("map pair not modified: got=%s", mp.String())
+		}
+	}
+
+	// a node of the wrong kind is not stored, the old one stays
+	callIntoOne := func(node Node) Node {
+		if _, ok := node.(*CallExpression); ok {
+			return one()
+		}
+		if _, ok := node.(*Identifier); ok {
+			return nil
+		}
+		return node
+	}
+	call := &CallExpression{Function: &Identifier{Value: "f"}}
+	spawn := &SpawnExpression{Call: call}
+	Modify(spawn, callIntoOne)
+	if spawn.Call != call || call.Function == nil {
+		t.Errorf("spawn.Call must keep the call: got=%#v", spawn.Call)
+	}
+	comp := &Comprehension{Value: one(), Variable: &Identifier{Value: "x"}, Iterable: one()}
+	Modify(comp, callIntoOne)
+	if comp.Variable == nil {
+		t.Errorf("comp.Variable must not be nil")
+	}
+}
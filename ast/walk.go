@@ -0,0 +1,178 @@
+package ast
+
+// Visit is called for every node found by Walk, the returned
+// visitor is used for the children and nil skips them
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// NOTE:
+// children are visited in source order, optional parts
+// that are missing (else, catch, slice bounds) are skipped.
+// after the children Visit(nil) is called like go/ast does
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+	case *ExpressionStatement:
+		Walk(v, n.Expression)
+	case *LetStatement:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+	case *ReturnStatement:
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+	case *AssignStatement:
+		Walk(v, n.Target)
+		Walk(v, n.Value)
+	case *ThrowStatement:
+		Walk(v, n.Value)
+	case *RecordStatement:
+		Walk(v, n.Name)
+		for _, f := range n.Fields {
+			Walk(v, f)
+		}
+	case *ClassStatement:
+		Walk(v, n.Name)
+		if n.Parent != nil {
+			Walk(v, n.Parent)
+		}
+		for _, m := range n.Methods {
+			Walk(v, m)
+		}
+	case *PrefixExpression:
+		Walk(v, n.Right)
+	case *InfixExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *IfElseExpression:
+		Walk(v, n.Predicate)
+		Walk(v, n.Consequence)
+		if n.Alternative != nil {
+			Walk(v, n.Alternative)
+		}
+	case *FunctionLiteral:
+		for _, p := range n.Parameters {
+			Walk(v, p)
+			if def, ok := n.Defaults[p.Value]; ok {
+				Walk(v, def)
+			}
+		}
+		if n.Rest != nil {
+			Walk(v, n.Rest)
+		}
+		Walk(v, n.Body)
+	case *MacroLiteral:
+		for _, p := range n.Parameters {
+			Walk(v, p)
+		}
+		Walk(v, n.Body)
+	case *YieldExpression:
+		Walk(v, n.Value)
+	case *SpawnExpression:
+		Walk(v, n.Call)
+	case *AwaitExpression:
+		Walk(v, n.Value)
+	case *CallExpression:
+		// x |> f(a) has x first in the source
+		if n.Piped {
+			Walk(v, n.Arguments[0])
+			Walk(v, n.Function)
+			walkExpressions(v, n.Arguments[1:])
+			break
+		}
+		Walk(v, n.Function)
+		walkExpressions(v, n.Arguments)
+	case *SpreadExpression:
+		Walk(v, n.Value)
+	case *NamedArgument:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+	case *ArrayLiteral:
+		walkExpressions(v, n.Elements)
+	case *SetLiteral:
+		walkExpressions(v, n.Elements)
+	case *MapLiteral:
+		for _, key := range n.Keys {
+			Walk(v, key)
+			Walk(v, n.Pairs[key])
+		}
+	case *IndexExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Index)
+	case *SliceExpression:
+		Walk(v, n.Left)
+		for _, exp := range []Expression{n.Start, n.End, n.Step} {
+			if exp != nil {
+				Walk(v, exp)
+			}
+		}
+	case *MemberExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Property)
+	case *ForExpression:
+		Walk(v, n.Condition)
+		Walk(v, n.Body)
+	case *Comprehension:
+		if n.Key != nil {
+			Walk(v, n.Key)
+		}
+		Walk(v, n.Value)
+		Walk(v, n.Variable)
+		Walk(v, n.Iterable)
+		if n.Condition != nil {
+			Walk(v, n.Condition)
+		}
+	case *ForEachExpression:
+		Walk(v, n.Variable)
+		Walk(v, n.Iterable)
+		Walk(v, n.Body)
+	case *TryExpression:
+		Walk(v, n.Block)
+		if n.Param != nil {
+			Walk(v, n.Param)
+		}
+		if n.Catch != nil {
+			Walk(v, n.Catch)
+		}
+		if n.Finally != nil {
+			Walk(v, n.Finally)
+		}
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, stmts []Statement) {
+	for _, stmt := range stmts {
+		Walk(v, stmt)
+	}
+}
+
+func walkExpressions(v Visitor, exps []Expression) {
+	for _, exp := range exps {
+		Walk(v, exp)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect calls f for every node in depth first order and
+// f(nil) after its children, returning false skips them
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,170 @@
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/lexer"
+	"github.com/dxtym/skibidi/parser"
+)
+
+// one statement per node type that has children,
+// method names are plain strings so they are not x
+const source = `
+amogus x = -x + 1;
+rizz x;
+x.y = x;
+yeet x;
+squad x { x };
+sigma x < x { cook m(x) { x } };
+hawk (x) { x } tuah { x };
+cook(x, w = x, ...x) { slay x };
+cheat(x) { x };
+x(...x, x = x);
+x |> x(x) |> x;
+sendit x(x);
+lowkey cook(x) { holdup x };
+[x, {x, x}, {x: x}, x[x], x[x:x:x], x?.x];
+mew (x) { x };
+mew (x in x) { x };
+[x mew x in x hawk x];
+{x: x mew x in x};
+sus { x } cope (x) { x } periodt { x };
+`
+
+func parse(t *testing.T, got string) *ast.Program {
+	l := lexer.NewLexer(got)
+	p := parser.NewParser(l)
+	program := p.Parse()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	return program
+}
+
+func TestInspect(t *testing.T) {
+	program := parse(t, source)
+
+	idents := 0
+	ast.Inspect(program, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok && ident.Value == "x" {
+			idents++
+		}
+		return true
+	})
+
+	want := strings.Count(source, "x")
+	if idents != want {
+		t.Errorf("idents not equal to %d: got=%d", want, idents)
+	}
+}
+
+func TestInspectOrder(t *testing.T) {
+	program := parse(t, "hawk (a) { b } tuah { c }; d[e:f]; {g: h, i: j}; k |> l(m)")
+
+	names := []string{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok {
+			names = append(names, ident.Value)
+		}
+		return true
+	})
+
+	got := strings.Join(names, "")
+	if got != "abcdefghijklm" {
+		t.Errorf("names not equal to abcdefghijklm: got=%s", got)
+	}
+}
+
+func TestInspectSkip(t *testing.T) {
+	program := parse(t, "a; cook(b) { c }; d")
+
+	names := []string{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok {
+			names = append(names, ident.Value)
+		}
+		_, fn := node.(*ast.FunctionLiteral)
+		return !fn
+	})
+
+	got := strings.Join(names, "")
+	if got != "ad" {
+		t.Errorf("names not equal to ad: got=%s", got)
+	}
+}
+
+type depthVisitor struct {
+	depth int
+	max   *int
+}
+
+func (v depthVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		return nil
+	}
+	if v.depth > *v.max {
+		*v.max = v.depth
+	}
+	return depthVisitor{depth: v.depth + 1, max: v.max}
+}
+
+func TestWalk(t *testing.T) {
+	// Program > ExpressionStatement > Infix > Infix > Integer
+	program := parse(t, "1 + 2 + 3")
+
+	max := 0
+	ast.Walk(depthVisitor{max: &max}, program)
+	if max != 4 {
+		t.Errorf("max not equal to 4: got=%d", max)
+	}
+}
+
+func TestModifyAllNodes(t *testing.T) {
+	program := parse(t, source)
+
+	renamed := ast.Modify(program, func(node ast.Node) ast.Node {
+		if ident, ok := node.(*ast.Identifier); ok && ident.Value == "x" {
+			return &ast.Identifier{Token: ident.Token, Value: "z"}
+		}
+		return node
+	})
+
+	// Identifier.String prints the value, not the token
+	want := parse(t, strings.ReplaceAll(source, "x", "z"))
+	if renamed.String() != want.String() {
+		t.Errorf("renamed.String not equal to %s: got=%s", want.String(), renamed.String())
+	}
+
+	left := 0
+	ast.Inspect(renamed, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok && ident.Value == "x" {
+			left++
+		}
+		return true
+	})
+	if left != 0 {
+		t.Errorf("%d identifiers not modified", left)
+	}
+}
+
+func ExampleInspect() {
+	l := lexer.NewLexer("amogus a = b + 1;")
+	program := parser.NewParser(l).Parse()
+
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node != nil {
+			fmt.Printf("%T\n", node)
+		}
+		return true
+	})
+	// Output:
+	// *ast.Program
+	// *ast.LetStatement
+	// *ast.Identifier
+	// *ast.InfixExpression
+	// *ast.Identifier
+	// *ast.IntegerLiteral
+}
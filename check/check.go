@@ -0,0 +1,563 @@
+package check
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/token"
+)
+
+// Error is a type mistake found before running
+type Error struct {
+	Line     int
+	Column   int
+	Message  string
+	Inferred bool // no annotation is involved, so it may never happen
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+type scope struct {
+	vars  map[string]*Type
+	outer *scope
+}
+
+func (s *scope) get(name string) (*Type, bool) {
+	for ; s != nil; s = s.outer {
+		if typ, ok := s.vars[name]; ok {
+			return typ, true
+		}
+	}
+	return nil, false
+}
+
+// function whose body is being checked
+type function struct {
+	want *Type   // declared return type, nil when missing
+	got  []*Type // types given to rizz
+}
+
+type checker struct {
+	scope *scope
+	funcs []*function // innermost last
+	cond  int         // depth of blocks that may not run
+	errs  []Error
+}
+
+// NOTE:
+// literals are inferred, but a mistake between inferred
+// types alone is marked Inferred: the code may never run
+// or the name may be rebound before it does. only mistakes
+// against an annotation are certain. names it does not
+// know (builtins, earlier repl lines) are any
+func Check(program *ast.Program) []Error {
+	c := &checker{scope: &scope{vars: make(map[string]*Type)}}
+	for _, stmt := range program.Statements {
+		c.statement(stmt)
+	}
+	return c.errs
+}
+
+func (c *checker) errorf(node ast.Node, inferred bool, format string, args ...any) {
+	tok := tokenOf(node)
+	c.errs = append(c.errs, Error{
+		Line:     tok.Line,
+		Column:   tok.Column,
+		Message:  fmt.Sprintf(format, args...),
+		Inferred: inferred,
+	})
+}
+
+// every node keeps its token in a Token field
+func tokenOf(node ast.Node) token.Token {
+	field := reflect.ValueOf(node).Elem().FieldByName("Token")
+	tok, _ := field.Interface().(token.Token)
+	return tok
+}
+
+func (c *checker) push() {
+	c.scope = &scope{vars: make(map[string]*Type), outer: c.scope}
+}
+
+func (c *checker) pop() {
+	c.scope = c.scope.outer
+}
+
+func (c *checker) bind(name string, typ *Type) {
+	c.scope.vars[name] = typ
+}
+
+// report got where want is expected, at the start of node,
+// other types involved decide whether it is inferred
+func (c *checker) expect(node ast.Node, got, want *Type, others ...*Type) {
+	if !assignable(got, want) {
+		inferred := !declared(append(others, got, want)...)
+		c.errorf(start(node), inferred, "got mogged: want %s, got %s", want, got)
+	}
+}
+
+// leftmost node of an expression, operators and calls
+// keep the token in the middle of their source
+func start(node ast.Node) ast.Node {
+	switch n := node.(type) {
+	case *ast.InfixExpression:
+		return start(n.Left)
+	case *ast.CallExpression:
+		if n.Piped {
+			return start(n.Arguments[0])
+		}
+		return start(n.Function)
+	case *ast.IndexExpression:
+		return start(n.Left)
+	case *ast.SliceExpression:
+		return start(n.Left)
+	case *ast.MemberExpression:
+		return start(n.Left)
+	default:
+		return node
+	}
+}
+
+func (c *checker) annotation(ann *ast.TypeAnnotation) *Type {
+	if ann == nil {
+		return nil
+	}
+
+	typ, msg := fromAnnotation(ann)
+	if msg != "" {
+		c.errorf(ann, false, "%s", msg)
+		return Any
+	}
+	return typ
+}
+
+// type of a statement used as the value of a block
+func (c *checker) statement(stmt ast.Statement) *Type {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		return c.expression(stmt.Expression)
+	case *ast.LetStatement:
+		want := c.annotation(stmt.Type)
+		// bound first so functions can call themselves
+		if fn, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+			if want != nil {
+				c.bind(stmt.Name.Value, want)
+			} else {
+				c.bind(stmt.Name.Value, c.signature(fn))
+			}
+		}
+
+		got := c.expression(stmt.Value)
+		if want != nil {
+			c.expect(stmt.Value, got, want)
+			got = want
+		}
+		// after a branch the name may still hold the old type
+		if prev, ok := c.scope.vars[stmt.Name.Value]; ok && c.cond > 0 && prev.String() != got.String() {
+			got = Any
+		}
+		c.bind(stmt.Name.Value, got)
+	case *ast.ReturnStatement:
+		var node ast.Node = stmt
+		got := Null
+		if stmt.Value != nil {
+			node, got = stmt.Value, c.expression(stmt.Value)
+		}
+		if len(c.funcs) > 0 {
+			fn := c.funcs[len(c.funcs)-1]
+			if fn.want != nil {
+				c.expect(node, got, fn.want)
+			}
+			fn.got = append(fn.got, got)
+		}
+	case *ast.AssignStatement:
+		c.expression(stmt.Target)
+		c.expression(stmt.Value)
+	case *ast.ThrowStatement:
+		c.expression(stmt.Value)
+	case *ast.RecordStatement:
+		c.bind(stmt.Name.Value, &Type{Kind: FUNC, Return: Any})
+	case *ast.ClassStatement:
+		c.bind(stmt.Name.Value, &Type{Kind: FUNC, Return: Any})
+		for _, m := range stmt.Methods {
+			c.push()
+			c.bind("self", Any)
+			c.bind("super", Any)
+			c.function(m)
+			c.pop()
+		}
+	case *ast.BlockStatement:
+		return c.block(stmt)
+	}
+	return Any
+}
+
+// block that may run any number of times
+func (c *checker) branch(block *ast.BlockStatement) *Type {
+	c.cond++
+	defer func() { c.cond-- }()
+	return c.block(block)
+}
+
+func (c *checker) block(block *ast.BlockStatement) *Type {
+	typ := Null
+	for _, stmt := range block.Statements {
+		typ = c.statement(stmt)
+	}
+	return typ
+}
+
+func (c *checker) expressions(exps []ast.Expression) []*Type {
+	types := []*Type{}
+	for _, exp := range exps {
+		types = append(types, c.expression(exp))
+	}
+	return types
+}
+
+// unify all types, an empty list is unknown
+func unifyAll(types []*Type) *Type {
+	if len(types) == 0 {
+		return nil
+	}
+
+	typ := types[0]
+	for _, t := range types[1:] {
+		typ = unify(typ, t)
+	}
+	return typ
+}
+
+func (c *checker) expression(exp ast.Expression) *Type {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return Int
+	case *ast.StringLiteral:
+		return String
+	case *ast.Boolean:
+		return Bool
+	case *ast.Identifier:
+		if typ, ok := c.scope.get(exp.Value); ok {
+			return typ
+		}
+		return Any
+	case *ast.PrefixExpression:
+		return c.prefix(exp)
+	case *ast.InfixExpression:
+		return c.infix(exp)
+	case *ast.IfElseExpression:
+		c.expression(exp.Predicate)
+		cons := c.branch(exp.Consequence)
+		if exp.Alternative == nil {
+			return unify(cons, Null)
+		}
+		return unify(cons, c.branch(exp.Alternative))
+	case *ast.FunctionLiteral:
+		return c.function(exp)
+	case *ast.CallExpression:
+		return c.call(exp)
+	case *ast.ArrayLiteral:
+		return &Type{Kind: ARRAY, Elem: unifyAll(c.expressions(exp.Elements))}
+	case *ast.SetLiteral:
+		return &Type{Kind: SET, Elem: unifyAll(c.expressions(exp.Elements))}
+	case *ast.MapLiteral:
+		keys, vals := []*Type{}, []*Type{}
+		for _, key := range exp.Keys {
+			keys = append(keys, c.expression(key))
+			vals = append(vals, c.expression(exp.Pairs[key]))
+		}
+		return &Type{Kind: MAP, Key: unifyAll(keys), Elem: unifyAll(vals)}
+	case *ast.IndexExpression:
+		return c.index(exp)
+	case *ast.SliceExpression:
+		left := c.expression(exp.Left)
+		for _, part := range []ast.Expression{exp.Start, exp.End, exp.Step} {
+			if part != nil {
+				c.expect(part, c.expression(part), Int, left)
+			}
+		}
+		if exp.Optional {
+			return Any
+		}
+		return left
+	case *ast.MemberExpression:
+		c.expression(exp.Left)
+		return Any
+	case *ast.ForExpression:
+		c.expression(exp.Condition)
+		c.branch(exp.Body)
+		return Any
+	case *ast.ForEachExpression:
+		c.bind(exp.Variable.Value, elemOf(c.expression(exp.Iterable)))
+		c.branch(exp.Body)
+		return Any
+	case *ast.Comprehension:
+		return c.comprehension(exp)
+	case *ast.TryExpression:
+		c.branch(exp.Block)
+		if exp.Catch != nil {
+			c.push()
+			if exp.Param != nil {
+				c.bind(exp.Param.Value, Any)
+			}
+			c.branch(exp.Catch)
+			c.pop()
+		}
+		if exp.Finally != nil {
+			c.block(exp.Finally)
+		}
+		return Any
+	case *ast.YieldExpression:
+		c.expression(exp.Value)
+		return Null
+	case *ast.AwaitExpression:
+		c.expression(exp.Value)
+		return Any
+	case *ast.SpawnExpression:
+		c.expression(exp.Call)
+		return Any
+	case *ast.SpreadExpression:
+		c.expression(exp.Value)
+		return Any
+	case *ast.NamedArgument:
+		return c.expression(exp.Value)
+	default:
+		return Any
+	}
+}
+
+func (c *checker) prefix(exp *ast.PrefixExpression) *Type {
+	right := c.expression(exp.Right)
+
+	switch exp.Operator {
+	case "!":
+		return Bool
+	case "-", "~":
+		if !assignable(right, Int) {
+			c.errorf(exp, !declared(right), "baka: %s%s", exp.Operator, right)
+		}
+		return Int
+	default:
+		return Any
+	}
+}
+
+// NOTE:
+// mirrors evalInfixExpression, so only pairs that
+// are certain to fail at runtime get reported
+func (c *checker) infix(exp *ast.InfixExpression) *Type {
+	left := c.expression(exp.Left)
+	right := c.expression(exp.Right)
+
+	switch exp.Operator {
+	case "==", "!=", "in":
+		return Bool
+	case "??":
+		if !isAny(left) && left.Kind == NULL {
+			return right
+		}
+		return unify(left, right)
+	}
+
+	if isAny(left) || isAny(right) {
+		switch exp.Operator {
+		case "<", ">":
+			return Bool
+		default:
+			return Any
+		}
+	}
+	if left.Kind != right.Kind {
+		c.errorf(exp, !declared(left, right), "touch grass: %s %s %s", left, exp.Operator, right)
+		return Any
+	}
+
+	switch {
+	case left.Kind == INT && (exp.Operator == "<" || exp.Operator == ">"):
+		return Bool
+	case left.Kind == INT:
+		return Int
+	case left.Kind == STRING && exp.Operator == "+":
+		return String
+	case left.Kind == STRING && (exp.Operator == "<" || exp.Operator == ">"):
+		return Bool
+	default:
+		c.errorf(exp, !declared(left, right), "delulu: %s %s %s", left, exp.Operator, right)
+		return Any
+	}
+}
+
+func (c *checker) index(exp *ast.IndexExpression) *Type {
+	left := c.expression(exp.Left)
+	index := c.expression(exp.Index)
+	if exp.Optional || isAny(left) {
+		return Any
+	}
+
+	switch left.Kind {
+	case ARRAY:
+		c.expect(exp.Index, index, Int, left)
+		return orAny(left.Elem)
+	case STRING:
+		c.expect(exp.Index, index, Int, left)
+		return String
+	case MAP:
+		c.expect(exp.Index, index, orAny(left.Key), left)
+		return orAny(left.Elem)
+	default:
+		return Any
+	}
+}
+
+// type of fn from its annotations alone
+func (c *checker) signature(fn *ast.FunctionLiteral) *Type {
+	typ := &Type{Kind: FUNC, Params: []*Type{}, Names: []string{}}
+	for _, p := range fn.Parameters {
+		typ.Params = append(typ.Params, orAny(c.annotation(fn.Types[p.Value])))
+		typ.Names = append(typ.Names, p.Value)
+	}
+	if fn.Rest != nil {
+		typ.Rest = orAny(c.annotation(fn.Types[fn.Rest.Value]))
+	}
+
+	typ.Return = orAny(c.annotation(fn.Return))
+	// calls give back a generator or promise instead
+	if fn.Generator || fn.Async {
+		typ.Return = Any
+	}
+	return typ
+}
+
+func (c *checker) function(fn *ast.FunctionLiteral) *Type {
+	typ := &Type{Kind: FUNC, Params: []*Type{}, Names: []string{}}
+	c.push()
+	cond := c.cond
+	c.cond = 0
+	defer func() {
+		c.pop()
+		c.cond = cond
+	}()
+
+	for _, p := range fn.Parameters {
+		want := c.annotation(fn.Types[p.Value])
+		if def, ok := fn.Defaults[p.Value]; ok {
+			got := c.expression(def)
+			if want != nil {
+				c.expect(def, got, want)
+			} else {
+				want = got // unannotated defaults give the type
+			}
+		}
+
+		want = orAny(want)
+		c.bind(p.Value, want)
+		typ.Params = append(typ.Params, want)
+		typ.Names = append(typ.Names, p.Value)
+	}
+	if fn.Rest != nil {
+		typ.Rest = orAny(c.annotation(fn.Types[fn.Rest.Value]))
+		c.bind(fn.Rest.Value, &Type{Kind: ARRAY, Elem: typ.Rest})
+	}
+
+	f := &function{want: c.annotation(fn.Return)}
+	c.funcs = append(c.funcs, f)
+	last := c.block(fn.Body)
+	c.funcs = c.funcs[:len(c.funcs)-1]
+
+	// the value of the last statement is returned too,
+	// unless it is a rizz that was already counted
+	if n := len(fn.Body.Statements); n > 0 && !fn.Generator {
+		if stmt, ok := fn.Body.Statements[n-1].(*ast.ExpressionStatement); ok {
+			if f.want != nil {
+				c.expect(stmt.Expression, last, f.want)
+			}
+			f.got = append(f.got, last)
+		}
+	}
+
+	switch {
+	case fn.Generator || fn.Async:
+		typ.Return = Any
+	case f.want != nil:
+		typ.Return = f.want
+	default:
+		typ.Return = orAny(unifyAll(f.got))
+	}
+	return typ
+}
+
+func (c *checker) call(exp *ast.CallExpression) *Type {
+	// quoted code is data, it only runs after expansion
+	if ident, ok := exp.Function.(*ast.Identifier); ok && (ident.Value == "quote" || ident.Value == "unquote") {
+		return Any
+	}
+
+	callee := c.expression(exp.Function)
+	fn := callee
+	if isAny(fn) || fn.Kind != FUNC || fn.Params == nil {
+		fn = nil
+	}
+
+	positional := true
+	for i, arg := range exp.Arguments {
+		switch arg := arg.(type) {
+		case *ast.SpreadExpression:
+			c.expression(arg)
+			positional = false // later positions are unknown
+		case *ast.NamedArgument:
+			got := c.expression(arg.Value)
+			if fn == nil {
+				continue
+			}
+			for j, name := range fn.Names {
+				if name == arg.Name.Value {
+					c.expect(arg.Value, got, fn.Params[j])
+				}
+			}
+		default:
+			got := c.expression(arg)
+			if fn == nil || !positional {
+				continue
+			}
+			if i < len(fn.Params) {
+				c.expect(arg, got, fn.Params[i])
+			} else if fn.Rest != nil {
+				c.expect(arg, got, fn.Rest)
+			}
+		}
+	}
+
+	if isAny(callee) || callee.Kind != FUNC {
+		return Any
+	}
+	return orAny(callee.Return)
+}
+
+func (c *checker) comprehension(exp *ast.Comprehension) *Type {
+	iter := c.expression(exp.Iterable)
+	c.push()
+	defer c.pop()
+
+	c.bind(exp.Variable.Value, elemOf(iter))
+	if exp.Condition != nil {
+		c.expression(exp.Condition)
+	}
+
+	var key *Type
+	if exp.Key != nil {
+		key = c.expression(exp.Key)
+	}
+	val := c.expression(exp.Value)
+
+	switch {
+	case exp.Key != nil:
+		return &Type{Kind: MAP, Key: key, Elem: val}
+	case exp.Token.Type == token.LBRACE:
+		return &Type{Kind: SET, Elem: val}
+	default:
+		return &Type{Kind: ARRAY, Elem: val}
+	}
+}
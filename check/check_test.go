@@ -0,0 +1,111 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/lexer"
+	"github.com/dxtym/skibidi/parser"
+)
+
+func parse(t *testing.T, got string) *ast.Program {
+	l := lexer.NewLexer(got)
+	p := parser.NewParser(l)
+	program := p.Parse()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors for %s: %v", got, p.Errors())
+	}
+	return program
+}
+
+func TestCheck(t *testing.T) {
+	tests := []string{
+		"amogus x: int = 1; amogus y: string = \"a\"; amogus z: bool = x > 1;",
+		"amogus xs: array[int] = [1, 2]; amogus m: map[string, int] = {\"a\": 1}; amogus s: set[int] = {1};",
+		"amogus add = cook(a: int, b: int): int { a + b }; amogus x: int = add(1, 2);",
+		"amogus fib = cook(n: int): int { hawk (n < 2) { rizz n; } fib(n - 1) + fib(n - 2) };",
+		"amogus f = cook(x: int = 1, ...r: string): int => x; f(2, \"a\", \"b\"); f(x = 3);",
+		"amogus twice = cook(f: cook(int): int, x: int): int => f(f(x)); twice(cook(x: int): int => x * 2, 1);",
+		"amogus h: cook = cook(x) { x }; amogus a: any = 1; amogus a: string = \"a\";",
+		"amogus xs: array[int] = [x * 2 mew x in [1, 2] hawk x > 1];",
+		"amogus n: int = 0; mew (x in [1, 2]) { amogus n = n + x; };",
+		// unknown values are never reported
+		"amogus f = cook(x) { x + 1 }; f(\"a\"); yap(1 + nope);",
+		"amogus x = 1; hawk (fax) { amogus x = \"a\"; }; x + 1;",
+		"sigma A { cook add(o: int): int { self.n + o } }; A().add(1);",
+		"amogus q = quote(1 + fax);",
+		"amogus g = cook(): int { slay 1 }; lowkey cook(): int { holdup 1 };",
+	}
+
+	for _, tt := range tests {
+		if errs := Check(parse(t, tt)); len(errs) > 0 {
+			t.Errorf("errs must be empty for %s: got=%v", tt, errs)
+		}
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	tests := []struct {
+		got      string
+		want     string
+		inferred bool
+	}{
+		{"amogus x: int = \"a\";", "1:17: got mogged: want int, got string", false},
+		{"amogus x: string = 1 + 2;", "1:20: got mogged: want string, got int", false},
+		{"amogus x: array[int] = [1, \"a\"]; amogus y: array[string] = [1];", "1:60: got mogged: want array[string], got array[int]", false},
+		{"amogus m: map[string, int] = {\"a\": \"b\"};", "1:30: got mogged: want map[string, int], got map[string, string]", false},
+		{"amogus f = cook(x: int) { x }; f(\"a\");", "1:34: got mogged: want int, got string", false},
+		{"amogus f = cook(x: int, y: int) { x }; f(y = fax);", "1:46: got mogged: want int, got bool", false},
+		{"amogus f = cook(...r: int) { r }; f(1, \"a\");", "1:40: got mogged: want int, got string", false},
+		{"amogus f = cook(x: int = \"a\") { x };", "1:26: got mogged: want int, got string", false},
+		{"amogus f = cook(): int { rizz \"a\"; };", "1:31: got mogged: want int, got string", false},
+		{"amogus f = cook(): int {\n  \"a\"\n};", "2:3: got mogged: want int, got string", false},
+		{"amogus f = cook(): string => 1;", "1:30: got mogged: want string, got int", false},
+		{"amogus x: int = 1;\namogus y = x + \"a\";", "2:14: touch grass: int + string", false},
+		{"fax * cap;", "1:5: delulu: bool * bool", true},
+		{"-\"a\";", "1:1: baka: -string", true},
+		{"[1, 2][\"a\"];", "1:8: got mogged: want int, got string", true},
+		{"amogus xs = [x mew x in [1]]; amogus y: string = xs[0];", "1:50: got mogged: want string, got int", false},
+		{"amogus f = cook(x: int): int => x; amogus g: cook(string): int = f;", "1:66: got mogged: want cook(string): int, got cook(int): int", false},
+		{"amogus x: foo = 1;", "1:11: delulu: type foo", false},
+		{"amogus x: array[int, int] = 1;", "1:11: mid type: array[int, int]", false},
+		// may never happen, so they do not stop the program
+		{"hawk (cap) { 1 + \"a\" }; 5", "1:16: touch grass: int + string", true},
+		{"amogus x = \"a\"; amogus f = cook() { x + 1 }; amogus x = 1; f()", "1:39: touch grass: string + int", true},
+		{"sus { 1 + \"a\" } cope (e) { e.kind }", "1:9: touch grass: int + string", true},
+	}
+
+	for _, tt := range tests {
+		errs := Check(parse(t, tt.got))
+		if len(errs) != 1 {
+			t.Errorf("errs must be 1 error for %s: got=%v", tt.got, errs)
+			continue
+		}
+		if errs[0].Error() != tt.want {
+			t.Errorf("errs[0] not equal to %s: got=%s", tt.want, errs[0])
+		}
+		if errs[0].Inferred != tt.inferred {
+			t.Errorf("errs[0].Inferred not equal to %t: got=%t", tt.inferred, errs[0].Inferred)
+		}
+	}
+}
+
+func TestTypeString(t *testing.T) {
+	tests := []struct {
+		got  *Type
+		want string
+	}{
+		{Int, "int"},
+		{&Type{Kind: ARRAY}, "array"},
+		{&Type{Kind: SET, Elem: String}, "set[string]"},
+		{&Type{Kind: MAP, Key: String, Elem: &Type{Kind: ARRAY, Elem: Int}}, "map[string, array[int]]"},
+		{&Type{Kind: FUNC, Params: []*Type{Int}, Rest: String, Return: Bool}, "cook(int, ...string): bool"},
+		{&Type{Kind: FUNC}, "cook"},
+	}
+
+	for _, tt := range tests {
+		if tt.got.String() != tt.want {
+			t.Errorf("got.String not equal to %s: got=%s", tt.want, tt.got.String())
+		}
+	}
+}
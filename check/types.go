@@ -0,0 +1,217 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dxtym/skibidi/ast"
+)
+
+// Kind names a type the same way annotations do
+type Kind string
+
+const (
+	ANY    Kind = "any"
+	INT    Kind = "int"
+	STRING Kind = "string"
+	BOOL   Kind = "bool"
+	NULL   Kind = "null"
+	ARRAY  Kind = "array"
+	SET    Kind = "set"
+	MAP    Kind = "map"
+	FUNC   Kind = "cook"
+)
+
+// NOTE:
+// a nil part is unknown and matches anything, so
+// array is array[any] and a bare cook is any function
+type Type struct {
+	Kind     Kind
+	Elem     *Type    // array and set elements, map values
+	Key      *Type    // map keys
+	Params   []*Type  // nil when the parameters are unknown
+	Names    []string // parameter names for named arguments
+	Rest     *Type    // elements of a ...rest parameter
+	Return   *Type
+	Declared bool // written in an annotation, not inferred
+}
+
+var (
+	Any    = &Type{Kind: ANY}
+	Int    = &Type{Kind: INT}
+	String = &Type{Kind: STRING}
+	Bool   = &Type{Kind: BOOL}
+	Null   = &Type{Kind: NULL}
+)
+
+func (t *Type) String() string {
+	if t == nil {
+		return string(ANY)
+	}
+
+	switch t.Kind {
+	case ARRAY, SET:
+		if t.Elem == nil {
+			return string(t.Kind)
+		}
+		return fmt.Sprintf("%s[%s]", t.Kind, t.Elem)
+	case MAP:
+		if t.Key == nil && t.Elem == nil {
+			return string(t.Kind)
+		}
+		return fmt.Sprintf("%s[%s, %s]", t.Kind, t.Key, t.Elem)
+	case FUNC:
+		if t.Params == nil {
+			return string(t.Kind)
+		}
+		params := []string{}
+		for _, p := range t.Params {
+			params = append(params, p.String())
+		}
+		if t.Rest != nil {
+			params = append(params, "..."+t.Rest.String())
+		}
+		return fmt.Sprintf("%s(%s): %s", t.Kind, strings.Join(params, ", "), t.Return)
+	default:
+		return string(t.Kind)
+	}
+}
+
+func isAny(t *Type) bool {
+	return t == nil || t.Kind == ANY
+}
+
+// whether a value of type got can be used where want is expected
+func assignable(got, want *Type) bool {
+	if isAny(got) || isAny(want) {
+		return true
+	}
+	if got.Kind != want.Kind {
+		return false
+	}
+
+	switch want.Kind {
+	case ARRAY, SET:
+		return assignable(got.Elem, want.Elem)
+	case MAP:
+		return assignable(got.Key, want.Key) && assignable(got.Elem, want.Elem)
+	case FUNC:
+		if got.Params == nil || want.Params == nil {
+			return assignable(got.Return, want.Return)
+		}
+		if len(got.Params) != len(want.Params) {
+			return false
+		}
+		for i := range want.Params {
+			if !assignable(want.Params[i], got.Params[i]) {
+				return false
+			}
+		}
+		return assignable(got.Return, want.Return)
+	default:
+		return true
+	}
+}
+
+// common type of a and b, anything mixed is any.
+// it is only declared when both sides are
+func unify(a, b *Type) *Type {
+	if isAny(a) || isAny(b) {
+		return Any
+	}
+	if a.String() != b.String() {
+		return Any
+	}
+	if a.Declared {
+		return b
+	}
+	return a
+}
+
+// whether any of types came from an annotation
+func declared(types ...*Type) bool {
+	for _, t := range types {
+		if t != nil && t.Declared {
+			return true
+		}
+	}
+	return false
+}
+
+// element type seen when iterating t with mew (x in t)
+func elemOf(t *Type) *Type {
+	if isAny(t) {
+		return Any
+	}
+
+	switch t.Kind {
+	case ARRAY, SET:
+		return orAny(t.Elem)
+	case MAP:
+		return orAny(t.Key)
+	case STRING:
+		return String
+	default:
+		return Any
+	}
+}
+
+func orAny(t *Type) *Type {
+	if t == nil {
+		return Any
+	}
+	return t
+}
+
+// turn an annotation into a declared type, the message explains a bad one
+func fromAnnotation(ann *ast.TypeAnnotation) (*Type, string) {
+	params := []*Type{}
+	for _, p := range ann.Params {
+		typ, msg := fromAnnotation(p)
+		if msg != "" {
+			return nil, msg
+		}
+		params = append(params, typ)
+	}
+
+	// how many element types each name takes
+	arity := map[Kind]int{ARRAY: 1, SET: 1, MAP: 2}
+	kind := Kind(ann.Name)
+	if kind != FUNC && len(params) > 0 && len(params) != arity[kind] {
+		return nil, fmt.Sprintf("mid type: %s", ann)
+	}
+
+	switch kind {
+	case ANY:
+		return Any, ""
+	case INT, STRING, BOOL, NULL:
+		return &Type{Kind: kind, Declared: true}, ""
+	case ARRAY, SET:
+		typ := &Type{Kind: kind, Declared: true}
+		if len(params) > 0 {
+			typ.Elem = params[0]
+		}
+		return typ, ""
+	case MAP:
+		typ := &Type{Kind: MAP, Declared: true}
+		if len(params) > 0 {
+			typ.Key, typ.Elem = params[0], params[1]
+		}
+		return typ, ""
+	case FUNC:
+		typ := &Type{Kind: FUNC, Return: Any, Declared: true}
+		if ann.Params != nil {
+			typ.Params = params
+		}
+		if ann.Return != nil {
+			ret, msg := fromAnnotation(ann.Return)
+			if msg != "" {
+				return nil, msg
+			}
+			typ.Return = ret
+		}
+		return typ, ""
+	default:
+		return nil, fmt.Sprintf("delulu: type %s", ann.Name)
+	}
+}
@@ -0,0 +1,312 @@
+package eval
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/object"
+)
+
+// Clock is where the event loop reads and waits for time
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// FakeClock starts at the unix epoch and only moves
+// when the loop sleeps, so timers fire instantly and in order
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFakeClock() *FakeClock {
+	return &FakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// callbacks return an error object or nil
+type callback func() object.Object
+
+type timer struct {
+	at  time.Time
+	seq int // keeps timers due at the same time in order
+	fn  callback
+}
+
+// NOTE:
+// the loop runs callbacks one at a time on whoever drives
+// it: RunLoop at the end of a program or a top level holdup
+type eventLoop struct {
+	mu       sync.Mutex
+	clock    Clock
+	ready    []callback
+	timers   []*timer // sorted by at, then seq
+	seq      int
+	rejected []*object.Promise
+}
+
+var events = &eventLoop{clock: realClock{}}
+
+// SetClock replaces the event loop with an empty one on clock
+func SetClock(clock Clock) {
+	events = &eventLoop{clock: clock}
+}
+
+// RunLoop runs callbacks and timers until none are left, the first
+// error from a callback or an unawaited rejection is returned
+func RunLoop() object.Object {
+	for {
+		more, err := events.step()
+		if err != nil {
+			return err
+		}
+		if !more {
+			return events.unhandled()
+		}
+	}
+}
+
+func (l *eventLoop) now() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.clock.Now()
+}
+
+func (l *eventLoop) enqueue(fn callback) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ready = append(l.ready, fn)
+}
+
+func (l *eventLoop) schedule(d time.Duration, fn callback) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	t := &timer{at: l.clock.Now().Add(d), seq: l.seq, fn: fn}
+	l.seq++
+
+	i := sort.Search(len(l.timers), func(i int) bool {
+		return l.timers[i].at.After(t.at)
+	})
+	l.timers = append(l.timers, nil)
+	copy(l.timers[i+1:], l.timers[i:])
+	l.timers[i] = t
+}
+
+// run one ready callback, or wait for the next timer
+func (l *eventLoop) step() (bool, object.Object) {
+	l.mu.Lock()
+	if len(l.ready) > 0 {
+		fn := l.ready[0]
+		l.ready = l.ready[1:]
+		l.mu.Unlock()
+		return true, fn()
+	}
+	if len(l.timers) == 0 {
+		l.mu.Unlock()
+		return false, nil
+	}
+	t := l.timers[0]
+	l.timers = l.timers[1:]
+	clock := l.clock
+	l.mu.Unlock()
+
+	if wait := t.at.Sub(clock.Now()); wait > 0 {
+		clock.Sleep(wait)
+	}
+	return true, t.fn()
+}
+
+func (l *eventLoop) unhandled() object.Object {
+	l.mu.Lock()
+	rejected := l.rejected
+	l.rejected = nil
+	l.mu.Unlock()
+
+	for _, p := range rejected {
+		if !p.Handled() {
+			res, _ := p.Result()
+			return res
+		}
+	}
+	return nil
+}
+
+// promises resolved with a promise follow it
+func settlePromise(p *object.Promise, val object.Object) {
+	if other, ok := val.(*object.Promise); ok {
+		other.Then(func() {
+			res, _ := other.Result()
+			settlePromise(p, res)
+		})
+		return
+	}
+
+	if p.Settle(val) && checkError(val) {
+		events.mu.Lock()
+		events.rejected = append(events.rejected, p)
+		events.mu.Unlock()
+	}
+}
+
+// NOTE:
+// like generators the body runs on its own goroutine, but
+// only one side runs at a time: the caller until the body
+// pauses, the body until it awaits something pending or ends
+type asyncCoroutine struct {
+	wake  chan struct{}
+	pause chan struct{}
+}
+
+func callAsync(fn *object.Function, env *object.Environment) object.Object {
+	promise := object.NewPromise()
+	co := &asyncCoroutine{wake: make(chan struct{}), pause: make(chan struct{})}
+	env.SetAwait(co.await)
+
+	go func() {
+		res := unwrapReturnValue(resolveTailCall(Eval(fn.Body, env)))
+		settlePromise(promise, res)
+		co.pause <- struct{}{}
+	}()
+
+	// runs synchronously up to the first pending holdup
+	<-co.pause
+	return promise
+}
+
+// called by holdup on the body goroutine
+func (co *asyncCoroutine) await(p *object.Promise) object.Object {
+	if res, ok := p.Result(); ok {
+		return res
+	}
+
+	p.Then(func() {
+		events.enqueue(func() object.Object {
+			co.wake <- struct{}{}
+			<-co.pause
+			return nil
+		})
+	})
+	co.pause <- struct{}{}
+	<-co.wake
+
+	res, _ := p.Result()
+	return res
+}
+
+func evalAwaitExpression(node *ast.AwaitExpression, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if checkError(val) {
+		return val
+	}
+
+	promise, ok := val.(*object.Promise)
+	if !ok {
+		return val // awaiting a plain value gives it back
+	}
+	if await, ok := env.Await(); ok {
+		return await(promise)
+	}
+
+	// top level holdup drives the loop itself
+	for {
+		if res, ok := promise.Result(); ok {
+			return res
+		}
+		more, err := events.step()
+		if err != nil {
+			return err
+		}
+		if !more {
+			return newError(object.BAD_OPERATOR, "holdup: promise never settles")
+		}
+	}
+}
+
+func millis(obj object.Object) (time.Duration, object.Object) {
+	ms, ok := obj.(*object.Integer)
+	if !ok {
+		return 0, newError(object.TYPE_MISMATCH, "got mogged: %s", obj.Type())
+	}
+	if ms.Value < 0 {
+		return 0, newError(object.BAD_ARGUMENT, "cooked: %d out of range", ms.Value)
+	}
+	return time.Duration(ms.Value) * time.Millisecond, nil
+}
+
+// NOTE:
+// timer builtins call back into the evaluator,
+// so they are added here to avoid an init cycle
+func init() {
+	for name, fn := range map[string]*object.Builtin{
+		// sleep(ms) gives a promise settled with null after ms
+		"sleep": {
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError(object.ARITY, "mid: want 1 args, got %d", len(args))
+				}
+				d, err := millis(args[0])
+				if err != nil {
+					return err
+				}
+
+				promise := object.NewPromise()
+				events.schedule(d, func() object.Object {
+					settlePromise(promise, NULL)
+					return nil
+				})
+				return promise
+			},
+		},
+		"setTimeout": {
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return newError(object.ARITY, "mid: want 2 args, got %d", len(args))
+				}
+				d, err := millis(args[1])
+				if err != nil {
+					return err
+				}
+
+				fn := args[0]
+				events.schedule(d, func() object.Object {
+					if res := applyFunctionArgs(fn, nil, nil); checkError(res) {
+						return res
+					}
+					return nil
+				})
+				return NULL
+			},
+		},
+		// milliseconds on the event loop clock
+		"now": {
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 0 {
+					return newError(object.ARITY, "mid: want 0 args, got %d", len(args))
+				}
+				return &object.Integer{Value: int(events.now().UnixMilli())}
+			},
+		},
+	} {
+		builtins[name] = fn
+	}
+}
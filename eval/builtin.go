@@ -1,8 +1,6 @@
 package eval
 
 import (
-	"fmt"
-
 	"github.com/dxtym/skibidi/object"
 )
 
@@ -11,7 +9,7 @@ var builtins = map[string]*object.Builtin{
 	"yap": {
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return &object.Error{Message: fmt.Sprintf("cant yap: %d", len(args))}
+				return newError(object.ARITY, "cant yap: %d", len(args))
 			}
 
 			switch obj := args[0].(type) {
@@ -22,14 +20,14 @@ var builtins = map[string]*object.Builtin{
 			case *object.Array:
 				return &object.Array{Elements: obj.Elements}
 			default:
-				return &object.Error{Message: fmt.Sprintf("got mogged: %v", obj.Type())}
+				return newError(object.TYPE_MISMATCH, "got mogged: %v", obj.Type())
 			}
 		},
 	},
 	"aura": {
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return &object.Error{Message: fmt.Sprintf("zero aura: %d", len(args))}
+				return newError(object.ARITY, "zero aura: %d", len(args))
 			}
 
 			switch obj := args[0].(type) {
@@ -38,8 +36,155 @@ var builtins = map[string]*object.Builtin{
 			case *object.Array:
 				return &object.Integer{Value: len(obj.Elements)}
 			default:
-				return &object.Error{Message: fmt.Sprintf("got mogged: %s", obj.Type())}
+				return newError(object.TYPE_MISMATCH, "got mogged: %s", obj.Type())
+			}
+		},
+	},
+	"set": {
+		Fn: func(args ...object.Object) object.Object {
+			switch len(args) {
+			case 0:
+				return object.NewSet()
+			case 1:
+				elems, err := iterElements(args[0])
+				if err != nil {
+					return err
+				}
+				return newSet(elems)
+			default:
+				return newError(object.ARITY, "mid: want 1 args, got %d", len(args))
+			}
+		},
+	},
+	"union": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError(object.ARITY, "mid: want 2 args, got %d", len(args))
+			}
+			return applySetOperation(unionSets, args[0], args[1])
+		},
+	},
+	"intersect": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError(object.ARITY, "mid: want 2 args, got %d", len(args))
+			}
+			return applySetOperation(intersectSets, args[0], args[1])
+		},
+	},
+	"diff": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError(object.ARITY, "mid: want 2 args, got %d", len(args))
+			}
+			return applySetOperation(diffSets, args[0], args[1])
+		},
+	},
+	// next(g) steps a generator, NULL or the default once it is done
+	"next": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError(object.ARITY, "mid: want 1 args, got %d", len(args))
+			}
+			gen, ok := args[0].(*object.Generator)
+			if !ok {
+				return newError(object.TYPE_MISMATCH, "got mogged: %s", args[0].Type())
+			}
+
+			val, ok := gen.Resume()
+			if ok {
+				return val
+			}
+			if len(args) == 2 {
+				return args[1]
+			}
+			return NULL
+		},
+	},
+	// chan() is unbuffered, chan(n) holds up to n values
+	"chan": {
+		Fn: func(args ...object.Object) object.Object {
+			switch len(args) {
+			case 0:
+				return &object.Channel{Ch: make(chan object.Object)}
+			case 1:
+				size, ok := args[0].(*object.Integer)
+				if !ok {
+					return newError(object.TYPE_MISMATCH, "got mogged: %s", args[0].Type())
+				}
+				if size.Value < 0 {
+					return newError(object.BAD_ARGUMENT, "cooked: %d out of range", size.Value)
+				}
+				return &object.Channel{Ch: make(chan object.Object, size.Value)}
+			default:
+				return newError(object.ARITY, "mid: want 1 args, got %d", len(args))
+			}
+		},
+	},
+	"send": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError(object.ARITY, "mid: want 2 args, got %d", len(args))
+			}
+			ch, ok := args[0].(*object.Channel)
+			if !ok {
+				return newError(object.TYPE_MISMATCH, "got mogged: %s", args[0].Type())
+			}
+			return sendChannel(ch, args[1])
+		},
+	},
+	// recv gives NULL once the chan is closed and drained
+	"recv": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.ARITY, "mid: want 1 args, got %d", len(args))
+			}
+			ch, ok := args[0].(*object.Channel)
+			if !ok {
+				return newError(object.TYPE_MISMATCH, "got mogged: %s", args[0].Type())
+			}
+			val, ok := <-ch.Ch
+			if !ok {
+				return NULL
+			}
+			return val
+		},
+	},
+	"close": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.ARITY, "mid: want 1 args, got %d", len(args))
+			}
+			ch, ok := args[0].(*object.Channel)
+			if !ok {
+				return newError(object.TYPE_MISMATCH, "got mogged: %s", args[0].Type())
+			}
+			return closeChannel(ch)
+		},
+	},
+	"select": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.ARITY, "mid: want 1 args, got %d", len(args))
+			}
+			chans, ok := args[0].(*object.Array)
+			if !ok {
+				return newError(object.TYPE_MISMATCH, "got mogged: %s", args[0].Type())
+			}
+			return selectChannels(chans.Elements)
+		},
+	},
+	// join waits for a task and gives back its result or error
+	"join": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.ARITY, "mid: want 1 args, got %d", len(args))
+			}
+			task, ok := args[0].(*object.Task)
+			if !ok {
+				return newError(object.TYPE_MISMATCH, "got mogged: %s", args[0].Type())
 			}
+			return task.Wait()
 		},
 	},
 }
@@ -0,0 +1,69 @@
+package eval
+
+import (
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/object"
+	"github.com/dxtym/skibidi/token"
+)
+
+// NOTE:
+// each element gets its own enclosed env, so the variable
+// shadows outer names, is gone afterwards and closures
+// made in one step keep that step's value
+func evalComprehension(node *ast.Comprehension, env *object.Environment) object.Object {
+	iter := Eval(node.Iterable, env)
+	if checkError(iter) {
+		return iter
+	}
+	elems, err := iterElements(iter)
+	if err != nil {
+		return err
+	}
+
+	keys := []object.Object{}
+	vals := []object.Object{}
+	for _, elem := range elems {
+		inner := object.NewEnclosedEnvironment(env)
+		inner.Set(node.Variable.Value, elem)
+
+		if node.Condition != nil {
+			cond := evalValue(node.Condition, inner)
+			if checkError(cond) {
+				return cond
+			}
+			if !checkTruthy(cond) {
+				continue
+			}
+		}
+
+		if node.Key != nil {
+			key := evalValue(node.Key, inner)
+			if checkError(key) {
+				return key
+			}
+			if !object.Hashable(key) {
+				return newError(object.UNHASHABLE, "delulu: %s", key.Type())
+			}
+			keys = append(keys, key)
+		}
+
+		val := evalValue(node.Value, inner)
+		if checkError(val) {
+			return val
+		}
+		vals = append(vals, val)
+	}
+
+	switch {
+	case node.Key != nil:
+		mp := object.NewMap()
+		for i, key := range keys {
+			mp.Set(key, vals[i])
+		}
+		return mp
+	case node.Token.Type == token.LBRACE:
+		return newSet(vals)
+	default:
+		return &object.Array{Elements: vals}
+	}
+}
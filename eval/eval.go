@@ -24,17 +24,42 @@ func Eval(root ast.Node, env *object.Environment) object.Object {
 	case *ast.BlockStatement:
 		return evalBlockStatements(root.Statements, env)
 	case *ast.ReturnStatement:
+		// the call runs in applyFunctionArgs once this frame is gone
+		if call, ok := tailCall(root.Value); ok {
+			tail, err := evalCall(call, env)
+			if err != nil {
+				return err
+			}
+			return &object.ReturnValue{Tail: tail}
+		}
 		val := Eval(root.Value, env)
 		if checkError(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
 	case *ast.LetStatement:
+		val := evalValue(root.Value, env)
+		if checkError(val) {
+			return val
+		}
+		return declare(env, root.Name.Value, val, root)
+	case *ast.RecordStatement:
+		fields := []string{}
+		for _, f := range root.Fields {
+			fields = append(fields, f.Value)
+		}
+		def := &object.RecordType{Name: root.Name.Value, Fields: fields}
+		return declare(env, root.Name.Value, def, root)
+	case *ast.ClassStatement:
+		return evalClassStatement(root, env)
+	case *ast.AssignStatement:
+		return evalAssignStatement(root, env)
+	case *ast.ThrowStatement:
 		val := Eval(root.Value, env)
 		if checkError(val) {
 			return val
 		}
-		env.Set(root.Name.Value, val)
+		return newThrownError(val)
 	// expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: root.Value}
@@ -45,19 +70,25 @@ func Eval(root ast.Node, env *object.Environment) object.Object {
 	case *ast.Boolean:
 		return boolToBooleanObject(root.Value)
 	case *ast.FunctionLiteral:
-		params := root.Parameters
-		body := root.Body
-		return &object.Function{Parameters: params, Body: body, Env: env}
+		return &object.Function{
+			Parameters: root.Parameters,
+			Defaults:   root.Defaults,
+			Rest:       root.Rest,
+			Body:       root.Body,
+			Env:        env,
+			Generator:  root.Generator,
+			Async:      root.Async,
+			Arrow:      root.Arrow,
+		}
 	case *ast.CallExpression:
-		fn := Eval(root.Function, env)
-		if checkError(fn) {
-			return fn
+		if arg, ok := isQuoteCall(root); ok {
+			return quote(arg, env)
 		}
-		args := evalExpressions(root.Arguments, env)
-		if len(args) == 1 && checkError(args[0]) {
-			return args[0]
+		call, err := evalCall(root, env)
+		if err != nil {
+			return err
 		}
-		return applyFunctionArgs(fn, args)
+		return applyFunctionArgs(call.Fn, call.Args, call.Named)
 	case *ast.PrefixExpression:
 		right := Eval(root.Right, env)
 		if checkError(right) {
@@ -69,6 +100,13 @@ func Eval(root ast.Node, env *object.Environment) object.Object {
 		if checkError(left) {
 			return left
 		}
+		// right side only runs when left is NULL
+		if root.Operator == "??" {
+			if left != NULL {
+				return left
+			}
+			return Eval(root.Right, env)
+		}
 		right := Eval(root.Right, env)
 		if checkError(right) {
 			return right
@@ -82,20 +120,32 @@ func Eval(root ast.Node, env *object.Environment) object.Object {
 			return arr[0]
 		}
 		return &object.Array{Elements: arr}
-	case *ast.IndexExpression:
-		left := Eval(root.Left, env)
-		if checkError(left) {
-			return left
-		}
-		right := Eval(root.Index, env)
-		if checkError(right) {
-			return right
-		}
-		return evalIndexExpression(left, right)
+	case *ast.IndexExpression, *ast.MemberExpression, *ast.SliceExpression:
+		res, _ := evalAccess(root.(ast.Expression), env)
+		return res
 	case *ast.MapLiteral:
 		return evalMapLiteral(root, env)
+	case *ast.SetLiteral:
+		return evalSetLiteral(root, env)
 	case *ast.ForExpression:
 		return evalForExpression(root, env)
+	case *ast.ForEachExpression:
+		return evalForEachExpression(root, env)
+	case *ast.YieldExpression:
+		return evalYieldExpression(root, env)
+	case *ast.Comprehension:
+		return evalComprehension(root, env)
+	case *ast.SpawnExpression:
+		return evalSpawnExpression(root, env)
+	case *ast.AwaitExpression:
+		return evalAwaitExpression(root, env)
+	case *ast.MacroLiteral:
+		return newError(object.MACRO_EXPANSION, "delulu: cheat outside top level")
+	case *ast.SpreadExpression:
+		// calls, arrays and sets unpack it themselves
+		return newError(object.BAD_OPERATOR, "delulu: ... outside call or array")
+	case *ast.TryExpression:
+		return evalTryExpression(root, env)
 	}
 
 	return nil
@@ -105,7 +155,7 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 	var res object.Object
 	for _, node := range stmts {
 		res = Eval(node, env)
-		switch res := res.(type) {
+		switch res := resolveTailCall(res).(type) {
 		case *object.ReturnValue:
 			return res.Value
 		case *object.Error:
@@ -145,8 +195,10 @@ func evalPrefixExpression(op string, right object.Object) object.Object {
 		return evalNotOperatorExpression(right)
 	case "-":
 		return evalMinusOperatorExpression(right)
+	case "~":
+		return evalBitNotOperatorExpression(right)
 	default:
-		return newError("delulu: %s %s", op, right.Type())
+		return newError(object.BAD_OPERATOR, "delulu: %s %s", op, right.Type())
 	}
 }
 
@@ -165,28 +217,42 @@ func evalNotOperatorExpression(right object.Object) object.Object {
 
 func evalMinusOperatorExpression(right object.Object) object.Object {
 	if right.Type() != object.INTEGER_OBJECT {
-		return newError("baka: -%s", right.Type())
+		return newError(object.TYPE_MISMATCH, "baka: -%s", right.Type())
 	}
 
 	val := right.(*object.Integer).Value
 	return &object.Integer{Value: -val}
 }
 
+func evalBitNotOperatorExpression(right object.Object) object.Object {
+	if right.Type() != object.INTEGER_OBJECT {
+		return newError(object.TYPE_MISMATCH, "baka: ~%s", right.Type())
+	}
+
+	val := right.(*object.Integer).Value
+	return &object.Integer{Value: ^val}
+}
+
 func evalInfixExpression(op string, left, right object.Object) object.Object {
+	if res, ok := evalOperatorMethod(op, left, right); ok {
+		return res
+	}
+
 	switch {
+	case op == "in":
+		return evalInExpression(left, right)
 	case left.Type() == object.INTEGER_OBJECT && right.Type() == object.INTEGER_OBJECT:
 		return evalIntegerInfixExpression(op, left, right)
 	case left.Type() == object.STRING_OBJECT && right.Type() == object.STRING_OBJECT:
-		return evalStringConcatInfixExpression(op, left, right)
-	// pointer comparison (works because of TRUE and FALSE)
+		return evalStringInfixExpression(op, left, right)
 	case op == "!=":
-		return boolToBooleanObject(left != right)
+		return boolToBooleanObject(!object.Equal(left, right))
 	case op == "==":
-		return boolToBooleanObject(left == right)
+		return boolToBooleanObject(object.Equal(left, right))
 	case left.Type() != right.Type():
-		return newError("touch grass: %s %s %s", left.Type(), op, right.Type())
+		return newError(object.TYPE_MISMATCH, "touch grass: %s %s %s", left.Type(), op, right.Type())
 	default:
-		return newError("delulu: %s %s %s", left.Type(), op, right.Type())
+		return newError(object.BAD_OPERATOR, "delulu: %s %s %s", left.Type(), op, right.Type())
 	}
 }
 
@@ -202,6 +268,20 @@ func evalIntegerInfixExpression(op string, left, right object.Object) object.Obj
 		return &object.Integer{Value: l * r}
 	case "/":
 		return &object.Integer{Value: l / r}
+	case "&":
+		return &object.Integer{Value: l & r}
+	case "|":
+		return &object.Integer{Value: l | r}
+	case "^":
+		return &object.Integer{Value: l ^ r}
+	case "<<", ">>":
+		if r < 0 {
+			return newError(object.BAD_ARGUMENT, "cooked: shift by %d", r)
+		}
+		if op == "<<" {
+			return &object.Integer{Value: l << r}
+		}
+		return &object.Integer{Value: l >> r}
 	case "<":
 		return boolToBooleanObject(l < r)
 	case ">":
@@ -211,19 +291,28 @@ func evalIntegerInfixExpression(op string, left, right object.Object) object.Obj
 	case "==":
 		return boolToBooleanObject(l == r)
 	default:
-		return newError("delulu: %s %s %s", left.Type(), op, right.Type())
+		return newError(object.BAD_OPERATOR, "delulu: %s %s %s", left.Type(), op, right.Type())
 	}
 }
 
-// TODO: add support for comparison == and !=
-func evalStringConcatInfixExpression(op string, left, right object.Object) object.Object {
-	if op != "+" {
-		return newError("touch grass: %s %s %s", left.Type(), op, right.Type())
-	}
-
+// strings are ordered lexicographically
+func evalStringInfixExpression(op string, left, right object.Object) object.Object {
 	l := left.(*object.String).Value
 	r := right.(*object.String).Value
-	return &object.String{Value: l + r}
+	switch op {
+	case "+":
+		return &object.String{Value: l + r}
+	case "<":
+		return boolToBooleanObject(l < r)
+	case ">":
+		return boolToBooleanObject(l > r)
+	case "!=":
+		return boolToBooleanObject(l != r)
+	case "==":
+		return boolToBooleanObject(l == r)
+	default:
+		return newError(object.BAD_OPERATOR, "touch grass: %s %s %s", left.Type(), op, right.Type())
+	}
 }
 
 // TODO: revise complicated logic
@@ -254,8 +343,8 @@ func checkTruthy(cond object.Object) bool {
 	}
 }
 
-func newError(format string, a ...any) object.Object {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
+func newError(kind object.ErrorKind, format string, a ...any) object.Object {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Kind: kind}
 }
 
 // to avoid errors being passed around
@@ -266,6 +355,23 @@ func checkError(obj object.Object) bool {
 	return false
 }
 
+// NOTE:
+// consts can't be declared again, builtins can be
+// shadowed like any name. site is the declaring node, so
+// a loop may rerun the same const. returns nil like let statements
+func declare(env *object.Environment, name string, val object.Object, site ast.Node) object.Object {
+	if prev, ok := env.ConstSite(name); ok && prev != site {
+		return newError(object.CONST_REASSIGN, "nocap: %s", name)
+	}
+
+	if let, ok := site.(*ast.LetStatement); ok && let.Const {
+		env.SetConst(name, val, site)
+	} else {
+		env.Set(name, val)
+	}
+	return nil
+}
+
 func evalIdentifer(node *ast.Identifier, env *object.Environment) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
@@ -273,13 +379,22 @@ func evalIdentifer(node *ast.Identifier, env *object.Environment) object.Object
 	if fn, ok := builtins[node.Value]; ok {
 		return fn
 	}
-	return newError("delulu: %s", node.Value)
+	return newError(object.UNKNOWN_IDENTIFIER, "delulu: %s", node.Value)
 }
 
 func evalExpressions(node []ast.Expression, env *object.Environment) []object.Object {
 	res := []object.Object{}
 	for _, arg := range node {
-		evaled := Eval(arg, env)
+		if spread, ok := arg.(*ast.SpreadExpression); ok {
+			evaled := evalSpreadExpression(spread, env)
+			if len(evaled) == 1 && checkError(evaled[0]) {
+				return evaled
+			}
+			res = append(res, evaled...)
+			continue
+		}
+
+		evaled := evalValue(arg, env)
 		// check for error and return immediately
 		if checkError(evaled) {
 			return []object.Object{evaled}
@@ -289,26 +404,177 @@ func evalExpressions(node []ast.Expression, env *object.Environment) []object.Ob
 	return res
 }
 
+// unpack ...<array> into separate values
+func evalSpreadExpression(node *ast.SpreadExpression, env *object.Environment) []object.Object {
+	evaled := Eval(node.Value, env)
+	if checkError(evaled) {
+		return []object.Object{evaled}
+	}
+
+	arr, ok := evaled.(*object.Array)
+	if !ok {
+		return []object.Object{newError(object.TYPE_MISMATCH, "delulu: ...%s", evaled.Type())}
+	}
+	return arr.Elements
+}
+
+// split call arguments into positional and named ones,
+// evaluated left to right as they appear in the source
+func evalArguments(node []ast.Expression, env *object.Environment) ([]object.Object, map[string]object.Object) {
+	positional := []object.Object{}
+	named := make(map[string]object.Object)
+
+	for _, arg := range node {
+		na, ok := arg.(*ast.NamedArgument)
+		if !ok {
+			evaled := evalExpressions([]ast.Expression{arg}, env)
+			if len(evaled) == 1 && checkError(evaled[0]) {
+				return evaled, nil
+			}
+			positional = append(positional, evaled...)
+			continue
+		}
+
+		if _, ok := named[na.Name.Value]; ok {
+			return []object.Object{newError(object.ARITY, "mid: %s given twice", na.Name.Value)}, nil
+		}
+		evaled := evalValue(na.Value, env)
+		if checkError(evaled) {
+			return []object.Object{evaled}, nil
+		}
+		named[na.Name.Value] = evaled
+	}
+
+	return positional, named
+}
+
+// calls in rizz position, quote must stay unevaluated
+func tailCall(node ast.Expression) (*ast.CallExpression, bool) {
+	call, ok := node.(*ast.CallExpression)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := isQuoteCall(call); ok {
+		return nil, false
+	}
+	return call, true
+}
+
+// evaluate callee and arguments without applying them
+func evalCall(node *ast.CallExpression, env *object.Environment) (*object.TailCall, object.Object) {
+	fn := Eval(node.Function, env)
+	if checkError(fn) {
+		return nil, fn
+	}
+	args, named := evalArguments(node.Arguments, env)
+	if len(args) == 1 && checkError(args[0]) {
+		return nil, args[0]
+	}
+	return &object.TailCall{Fn: fn, Args: args, Named: named}, nil
+}
+
 // enclose inner scope with outer scope for functions
-func applyFunctionArgs(fn object.Object, args []object.Object) object.Object {
+func applyFunctionArgs(fn object.Object, args []object.Object, named map[string]object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		env := extendEnv(fn, args)
-		res := Eval(fn.Body, env)
-		return unwrapReturnValue(res)
+		// NOTE:
+		// tail calls come back as return values and are
+		// run by this loop so the go stack stays flat
+		for {
+			env, err := extendEnv(fn, args, named)
+			if err != nil {
+				return err
+			}
+			if fn.Async {
+				return callAsync(fn, env)
+			}
+			if fn.Generator {
+				return newGenerator(fn, env)
+			}
+
+			res := Eval(fn.Body, env)
+			rv, ok := res.(*object.ReturnValue)
+			if !ok || rv.Tail == nil {
+				return unwrapReturnValue(res)
+			}
+
+			next, ok := rv.Tail.Fn.(*object.Function)
+			if !ok {
+				return applyFunctionArgs(rv.Tail.Fn, rv.Tail.Args, rv.Tail.Named)
+			}
+			fn, args, named = next, rv.Tail.Args, rv.Tail.Named
+		}
 	case *object.Builtin:
+		for name := range named {
+			return newError(object.ARITY, "mid: unknown %s", name)
+		}
 		return fn.Fn(args...) // unwrap arguments
+	case *object.RecordType:
+		return newRecord(fn, args, named)
+	case *object.Class:
+		return newInstance(fn, args, named)
 	default:
-		return newError("delulu: %s", fn.Type())
+		return newError(object.NOT_CALLABLE, "delulu: %s", fn.Type())
 	}
 }
 
-func extendEnv(fn *object.Function, args []object.Object) *object.Environment {
+// bind positional, named, default and rest arguments to parameters
+func extendEnv(fn *object.Function, args []object.Object, named map[string]object.Object) (*object.Environment, object.Object) {
+	if len(args) > len(fn.Parameters) && fn.Rest == nil {
+		return nil, newError(object.ARITY, "mid: want %d args, got %d", len(fn.Parameters), len(args))
+	}
+
 	env := object.NewEnclosedEnvironment(fn.Env)
+	used := 0
 	for i, p := range fn.Parameters {
-		env.Set(p.Value, args[i])
+		val, ok := named[p.Value]
+		switch {
+		case i < len(args) && ok:
+			return nil, newError(object.ARITY, "mid: %s given twice", p.Value)
+		case i < len(args):
+			val = args[i]
+		case ok:
+			used++
+		default:
+			def, ok := fn.Defaults[p.Value]
+			if !ok {
+				return nil, newError(object.ARITY, "mid: missing %s", p.Value)
+			}
+			// defaults may refer to earlier parameters
+			val = Eval(def, env)
+			if checkError(val) {
+				return nil, val
+			}
+		}
+		env.Set(p.Value, val)
+	}
+
+	if used < len(named) {
+		for name := range named {
+			if !hasParameter(fn, name) {
+				return nil, newError(object.ARITY, "mid: unknown %s", name)
+			}
+		}
 	}
-	return env
+
+	if fn.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(fn.Parameters) {
+			rest = append(rest, args[len(fn.Parameters):]...)
+		}
+		env.Set(fn.Rest.Value, &object.Array{Elements: rest})
+	}
+
+	return env, nil
+}
+
+func hasParameter(fn *object.Function, name string) bool {
+	for _, p := range fn.Parameters {
+		if p.Value == name {
+			return true
+		}
+	}
+	return false
 }
 
 // NOTE:
@@ -321,69 +587,232 @@ func unwrapReturnValue(res object.Object) object.Object {
 	return res
 }
 
+// run a pending tail call where no trampoline is waiting for it
+func resolveTailCall(res object.Object) object.Object {
+	rv, ok := res.(*object.ReturnValue)
+	if !ok || rv.Tail == nil {
+		return res
+	}
+
+	val := applyFunctionArgs(rv.Tail.Fn, rv.Tail.Args, rv.Tail.Named)
+	if checkError(val) {
+		return val
+	}
+	return &object.ReturnValue{Value: val}
+}
+
+// value of an expression that is stored, not returned, so a
+// rizz inside it gives a plain value that sets and maps can hash
+func evalValue(node ast.Expression, env *object.Environment) object.Object {
+	return unwrapReturnValue(resolveTailCall(Eval(node, env)))
+}
+
 func evalIndexExpression(left, right object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJECT && right.Type() == object.INTEGER_OBJECT:
 		return evalArrayIndexExpression(left, right)
+	case left.Type() == object.STRING_OBJECT && right.Type() == object.INTEGER_OBJECT:
+		return evalStringIndexExpression(left, right)
 	case left.Type() == object.MAP_OBJECT:
 		return evalMapIndexExpression(left, right)
+	case left.Type() == object.INSTANCE_OBJECT:
+		if res, ok := evalOperatorMethod("[]", left, right); ok {
+			return res
+		}
+		return newError(object.TYPE_MISMATCH, "delulu: %s", left.Type())
 	default:
-		return newError("delulu: %s", left.Type())
+		return newError(object.TYPE_MISMATCH, "delulu: %s", left.Type())
+	}
+}
+
+// negative indexes count from the end
+func normalizeIndex(idx, length int) (int, bool) {
+	if idx < 0 {
+		idx += length
 	}
+	return idx, idx >= 0 && idx < length
 }
 
 func evalArrayIndexExpression(left, right object.Object) object.Object {
 	arr := left.(*object.Array)
-	idx := right.(*object.Integer).Value
-	max := len(arr.Elements) - 1
-
-	if idx < 0 || idx > max {
-		return NULL // TODO: return error
+	idx, ok := normalizeIndex(right.(*object.Integer).Value, len(arr.Elements))
+	if !ok {
+		return newError(object.INDEX_OUT_OF_RANGE, "cooked: %d out of range", right.(*object.Integer).Value)
 	}
 
 	return arr.Elements[idx]
 }
 
+func evalStringIndexExpression(left, right object.Object) object.Object {
+	str := left.(*object.String)
+	idx, ok := normalizeIndex(right.(*object.Integer).Value, len(str.Value))
+	if !ok {
+		return newError(object.INDEX_OUT_OF_RANGE, "cooked: %d out of range", right.(*object.Integer).Value)
+	}
+
+	return &object.String{Value: string(str.Value[idx])}
+}
+
+// NOTE:
+// x[i], x.y and x[a:b] chain into each other. a null
+// met at a ?. link ends the whole chain with null,
+// the bool tells the outer links to skip themselves
+func evalAccess(node ast.Expression, env *object.Environment) (object.Object, bool) {
+	var left ast.Expression
+	var optional bool
+	switch node := node.(type) {
+	case *ast.IndexExpression:
+		left, optional = node.Left, node.Optional
+	case *ast.MemberExpression:
+		left, optional = node.Left, node.Optional
+	case *ast.SliceExpression:
+		left, optional = node.Left, node.Optional
+	default:
+		return Eval(node, env), false
+	}
+
+	obj, skipped := evalAccess(left, env)
+	if skipped || (optional && obj == NULL) {
+		return NULL, true
+	}
+	if checkError(obj) {
+		return obj, false
+	}
+
+	switch node := node.(type) {
+	case *ast.IndexExpression:
+		right := Eval(node.Index, env)
+		if checkError(right) {
+			return right, false
+		}
+		return evalIndexExpression(obj, right), false
+	case *ast.MemberExpression:
+		return evalMemberExpression(obj, node.Property.Value), false
+	default:
+		return evalSliceExpression(node.(*ast.SliceExpression), obj, env), false
+	}
+}
+
+func evalSliceExpression(node *ast.SliceExpression, left object.Object, env *object.Environment) object.Object {
+	// omitted parts stay nil and get defaults in sliceIndices
+	bounds := make([]*int, 3)
+	for i, exp := range []ast.Expression{node.Start, node.End, node.Step} {
+		if exp == nil {
+			continue
+		}
+		val := Eval(exp, env)
+		if checkError(val) {
+			return val
+		}
+		integer, ok := val.(*object.Integer)
+		if !ok {
+			return newError(object.TYPE_MISMATCH, "delulu: %s[%s]", left.Type(), val.Type())
+		}
+		bounds[i] = &integer.Value
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		idxs, err := sliceIndices(len(left.Elements), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		elems := make([]object.Object, 0, len(idxs))
+		for _, i := range idxs {
+			elems = append(elems, left.Elements[i])
+		}
+		return &object.Array{Elements: elems}
+	case *object.String:
+		idxs, err := sliceIndices(len(left.Value), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		out := make([]byte, 0, len(idxs))
+		for _, i := range idxs {
+			out = append(out, left.Value[i])
+		}
+		return &object.String{Value: string(out)}
+	default:
+		return newError(object.TYPE_MISMATCH, "delulu: %s", left.Type())
+	}
+}
+
+// NOTE:
+// python slicing rules: bounds are clamped,
+// negative ones count from the end
+func sliceIndices(length int, start, end, step *int) ([]int, object.Object) {
+	s, e, st := 0, length, 1
+	if step != nil {
+		st = *step
+	}
+	if st == 0 {
+		return nil, newError(object.INDEX_OUT_OF_RANGE, "cooked: step 0")
+	}
+	if st < 0 {
+		s, e = length-1, -1
+	}
+
+	clamp := func(idx int) int {
+		if idx < 0 {
+			idx += length
+		}
+		lo, hi := 0, length
+		if st < 0 {
+			lo, hi = -1, length-1
+		}
+		return max(lo, min(idx, hi))
+	}
+	if start != nil {
+		s = clamp(*start)
+	}
+	if end != nil {
+		e = clamp(*end)
+	}
+
+	idxs := []int{}
+	for i := s; (st > 0 && i < e) || (st < 0 && i > e); i += st {
+		idxs = append(idxs, i)
+	}
+	return idxs, nil
+}
+
 func evalMapLiteral(node *ast.MapLiteral, env *object.Environment) object.Object {
-	mp := make(map[object.Hash]object.Pair)
+	mp := object.NewMap()
 
-	for key, val := range node.Pairs {
-		k := Eval(key, env)
+	for _, key := range node.Keys {
+		k := evalValue(key, env)
 		if checkError(k) {
 			return k
 		}
 
-		h, ok := k.(object.Hasher)
-		if !ok {
-			return newError("delulu: %s", k.Type())
+		if !object.Hashable(k) {
+			return newError(object.UNHASHABLE, "delulu: %s", k.Type())
 		}
 
-		v := Eval(val, env)
+		v := evalValue(node.Pairs[key], env)
 		if checkError(v) {
 			return v
 		}
 
-		hashed := h.Hash()
-		mp[hashed] = object.Pair{Key: k, Value: v}
+		mp.Set(k, v)
 	}
 
-	return &object.Map{Pairs: mp}
+	return mp
 }
 
 func evalMapIndexExpression(left, right object.Object) object.Object {
 	mp := left.(*object.Map)
 
-	key, ok := right.(object.Hasher)
-	if !ok {
-		return newError("delulu: %s", right.Type())
+	if !object.Hashable(right) {
+		return newError(object.UNHASHABLE, "delulu: %s", right.Type())
 	}
 
-	pair, ok := mp.Pairs[key.Hash()]
+	val, ok := mp.Get(right)
 	if !ok {
 		return NULL
 	}
 
-	return pair.Value
+	return val
 }
 
 func evalForExpression(node *ast.ForExpression, env *object.Environment) object.Object {
@@ -395,7 +824,9 @@ func evalForExpression(node *ast.ForExpression, env *object.Environment) object.
 
 		if checkTruthy(cond) {
 			body := Eval(node.Body, env)
-			if body == nil { continue }
+			if body == nil {
+				continue
+			}
 			if body.Type() == object.RETURN_VAL_OBJECT || body.Type() == object.ERROR_OBJECT {
 				return body
 			}
@@ -403,4 +834,225 @@ func evalForExpression(node *ast.ForExpression, env *object.Environment) object.
 			return &object.Boolean{Value: false}
 		}
 	}
-}
\ No newline at end of file
+}
+
+// fields are filled positionally or by name
+func newRecord(def *object.RecordType, args []object.Object, named map[string]object.Object) object.Object {
+	if len(args) > len(def.Fields) {
+		return newError(object.ARITY, "mid: want %d args, got %d", len(def.Fields), len(args))
+	}
+
+	rec := &object.Record{Def: def, Fields: make(map[string]object.Object)}
+	for i, val := range args {
+		rec.Set(def.Fields[i], val)
+	}
+	for name, val := range named {
+		if !def.HasField(name) {
+			return newError(object.UNKNOWN_MEMBER, "delulu: %s.%s", def.Name, name)
+		}
+		if _, ok := rec.Get(name); ok {
+			return newError(object.ARITY, "mid: %s given twice", name)
+		}
+		rec.Set(name, val)
+	}
+
+	for _, name := range def.Fields {
+		if _, ok := rec.Get(name); !ok {
+			return newError(object.ARITY, "mid: missing %s", name)
+		}
+	}
+	return rec
+}
+
+func evalMemberExpression(left object.Object, name string) object.Object {
+	switch left := left.(type) {
+	case *object.Record:
+		val, ok := left.Get(name)
+		if !ok {
+			return newError(object.UNKNOWN_MEMBER, "delulu: %s.%s", left.Def.Name, name)
+		}
+		return val
+	case *object.Instance:
+		if val, ok := left.Get(name); ok {
+			return val
+		}
+		if fn, owner := left.Class.FindMethod(name); fn != nil {
+			return bindMethod(left, fn, owner)
+		}
+		return newError(object.UNKNOWN_MEMBER, "delulu: %s.%s", left.Class.Name, name)
+	case *object.Super:
+		if fn, owner := left.Class.FindMethod(name); fn != nil {
+			return bindMethod(left.Receiver, fn, owner)
+		}
+		return newError(object.UNKNOWN_MEMBER, "delulu: %s.%s", left.Class.Name, name)
+	case *object.Map:
+		// m.y is m["y"] unless y names a map method
+		if method, ok := lookupMethod(left, name); ok {
+			return method
+		}
+		return evalMapIndexExpression(left, &object.String{Value: name})
+	default:
+		if method, ok := lookupMethod(left, name); ok {
+			return method
+		}
+		return newError(object.UNKNOWN_MEMBER, "delulu: %s.%s", left.Type(), name)
+	}
+}
+
+func evalAssignStatement(node *ast.AssignStatement, env *object.Environment) object.Object {
+	target := node.Target.(*ast.MemberExpression)
+	left := Eval(target.Left, env)
+	if checkError(left) {
+		return left
+	}
+
+	val := evalValue(node.Value, env)
+	if checkError(val) {
+		return val
+	}
+
+	name := target.Property.Value
+	switch left := left.(type) {
+	case *object.Record:
+		if !left.Def.HasField(name) {
+			return newError(object.UNKNOWN_MEMBER, "delulu: %s.%s", left.Def.Name, name)
+		}
+		left.Set(name, val)
+	case *object.Instance:
+		left.Set(name, val)
+	default:
+		return newError(object.UNKNOWN_MEMBER, "delulu: %s.%s", left.Type(), name)
+	}
+
+	return nil
+}
+
+func evalClassStatement(node *ast.ClassStatement, env *object.Environment) object.Object {
+	class := &object.Class{Name: node.Name.Value, Methods: make(map[string]*object.Function)}
+
+	if node.Parent != nil {
+		parent, ok := env.Get(node.Parent.Value)
+		if !ok {
+			return newError(object.UNKNOWN_IDENTIFIER, "delulu: %s", node.Parent.Value)
+		}
+		class.Parent, ok = parent.(*object.Class)
+		if !ok {
+			return newError(object.TYPE_MISMATCH, "delulu: < %s", parent.Type())
+		}
+	}
+
+	for _, m := range node.Methods {
+		class.Methods[m.Name] = &object.Function{
+			Parameters: m.Parameters,
+			Defaults:   m.Defaults,
+			Rest:       m.Rest,
+			Body:       m.Body,
+			Env:        env,
+			Arrow:      m.Arrow,
+		}
+	}
+
+	return declare(env, node.Name.Value, class, node)
+}
+
+// init runs on the fresh instance when defined
+func newInstance(class *object.Class, args []object.Object, named map[string]object.Object) object.Object {
+	inst := &object.Instance{Class: class, Fields: make(map[string]object.Object)}
+	setInspect(inst)
+
+	init, owner := class.FindMethod("init")
+	if init == nil {
+		if len(args) > 0 || len(named) > 0 {
+			return newError(object.ARITY, "mid: want 0 args, got %d", len(args)+len(named))
+		}
+		return inst
+	}
+
+	res := applyFunctionArgs(bindMethod(inst, init, owner), args, named)
+	if checkError(res) {
+		return res
+	}
+	return inst
+}
+
+// NOTE:
+// method sees self and super through an extra
+// scope between its closure and its parameters
+func bindMethod(recv *object.Instance, fn *object.Function, owner *object.Class) *object.Function {
+	env := object.NewEnclosedEnvironment(fn.Env)
+	env.Set("self", recv)
+	if owner.Parent != nil {
+		env.Set("super", &object.Super{Receiver: recv, Class: owner.Parent})
+	}
+
+	bound := *fn
+	bound.Env = env
+	return &bound
+}
+
+// caught runtime errors are exposed to scripts as records
+var errorRecord = &object.RecordType{Name: "Error", Fields: []string{"message", "kind", "code"}}
+
+func newThrownError(val object.Object) object.Object {
+	// rethrowing a caught error keeps its message and kind
+	if rec, ok := val.(*object.Record); ok && rec.Def == errorRecord {
+		m, _ := rec.Get("message")
+		c, _ := rec.Get("code")
+		msg, _ := m.(*object.String)
+		code, _ := c.(*object.String)
+		if msg != nil && code != nil {
+			if kind, ok := object.LookupErrorKind(code.Value); ok {
+				return &object.Error{Message: msg.Value, Kind: kind}
+			}
+		}
+	}
+
+	return &object.Error{Message: "yeet: " + val.Inspect(), Kind: object.THROWN, Value: val}
+}
+
+// NOTE:
+// thrown values are caught as they are, runtime
+// errors become records with message, kind and code
+func caughtValue(err *object.Error) object.Object {
+	if err.Value != nil {
+		return err.Value
+	}
+
+	return &object.Record{
+		Def: errorRecord,
+		Fields: map[string]object.Object{
+			"message": &object.String{Value: err.Message},
+			"kind":    &object.String{Value: err.Kind.Name},
+			"code":    &object.String{Value: err.Kind.Code},
+		},
+	}
+}
+
+// NOTE:
+// tail calls are resolved here, errors they raise belong
+// to this try and finally must run after them
+func evalTryExpression(node *ast.TryExpression, env *object.Environment) object.Object {
+	res := resolveTailCall(Eval(node.Block, env))
+
+	if err, ok := res.(*object.Error); ok && node.Catch != nil {
+		// the parameter only lives in the catch block
+		inner := object.NewEnclosedEnvironment(env)
+		if node.Param != nil {
+			inner.Set(node.Param.Value, caughtValue(err))
+		}
+		res = resolveTailCall(Eval(node.Catch, inner))
+	}
+
+	if node.Finally != nil {
+		// errors and returns from finally take over
+		fin := Eval(node.Finally, env)
+		if fin != nil {
+			ft := fin.Type()
+			if ft == object.ERROR_OBJECT || ft == object.RETURN_VAL_OBJECT {
+				return fin
+			}
+		}
+	}
+
+	return res
+}
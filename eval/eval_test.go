@@ -1,8 +1,12 @@
 package eval_test
 
 import (
+	"runtime"
+	"runtime/debug"
 	"testing"
+	"time"
 
+	"github.com/dxtym/skibidi/ast"
 	"github.com/dxtym/skibidi/eval"
 	"github.com/dxtym/skibidi/lexer"
 	"github.com/dxtym/skibidi/object"
@@ -39,6 +43,15 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"4 / 2", 2},
 		{"(1 + 2) * 3", 9},
 		{"6 / (1 - 3)", -3},
+		{"0x10 + 0b11 + 0o7 + 1_000", 1026},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"~5", -6},
+		{"1 << 10", 1024},
+		{"-16 >> 2", -4},
+		{"1 + 2 << 3", 24},
+		{"1 | 2 ^ 3 & 4", 3},
 	}
 
 	for _, tt := range tests {
@@ -106,6 +119,25 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"fax == cap", false},
 		{"fax != cap", true},
 		{"cap != fax", true},
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" < "b"`, true},
+		{`"ab" < "a"`, false},
+		{`"b" > "a"`, true},
+		{`"abc" > "abd"`, false},
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, [2, 3]] == [1, [2, 3]]", true},
+		{"[1, 2] != [1, 2, 3]", true},
+		{"[] == []", true},
+		{`{"a": 1, 2: [3]} == {2: [3], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`{"a": 1} != {"a": 1, "b": 2}`, true},
+		{"hawk (cap) { 1 } == hawk (cap) { 2 }", true},
+		{`1 == "1"`, false},
+		{"[1] != 1", true},
 	}
 
 	for _, tt := range tests {
@@ -205,6 +237,9 @@ func TestErrorHandling(t *testing.T) {
 		{"1 - fax; 1;", "touch grass: INTEGER - BOOLEAN"},
 		{"foobar;", "delulu: foobar"},
 		{`"foobar" - "barfoo";`, "touch grass: STRING - STRING"},
+		{`[1] < [2];`, "delulu: ARRAY < ARRAY"},
+		{`{[cook(x) { x }]: 1};`, "delulu: ARRAY"},
+		{`{"a": 1}[[{}]];`, "delulu: ARRAY"},
 	}
 
 	for _, tt := range tests {
@@ -220,6 +255,23 @@ func TestErrorHandling(t *testing.T) {
 	}
 }
 
+func TestBitwiseErrors(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"1 << -1", "cooked: shift by -1"},
+		{`~"a"`, "baka: ~STRING"},
+		{"fax & cap", "delulu: BOOLEAN & BOOLEAN"},
+		{`1 | "a"`, "touch grass: INTEGER | STRING"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		testErrorObject(t, evaled, tt.want)
+	}
+}
+
 func TestLetStatement(t *testing.T) {
 	tests := []struct {
 		got  string
@@ -325,19 +377,98 @@ func TestArrayIndexExpression(t *testing.T) {
 		{"[1, 2, 3][0]", 1},
 		{"[1, 2, 3][1]", 2},
 		{"[1, 2, 3][2]", 3},
-		{"[1, 2, 3][3]", nil},
-		{"[1, 2, 3][-1]", nil},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[1, 2, 3][3]", "cooked: 3 out of range"},
+		{"[1, 2, 3][-4]", "cooked: -4 out of range"},
 	}
 
 	for _, tt := range tests {
 		evaled := testEval(tt.got)
-		val, ok := tt.want.(int)
-		if ok {
-			testIntegerObject(t, evaled, val)
-		} else {
-			testNullObject(t, evaled)
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case string:
+			testErrorObject(t, evaled, want)
+		}
+	}
+}
+
+func testErrorObject(t *testing.T, eval object.Object, msg string) bool {
+	err, ok := eval.(*object.Error)
+	if !ok {
+		t.Errorf("eval not *object.Error: got=%T (%+v)", eval, eval)
+		return false
+	}
+	if err.Message != msg {
+		t.Errorf("err.Message not equal to %s: got=%s", msg, err.Message)
+		return false
+	}
+	return true
+}
+
+func TestStringIndexExpression(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{`"abc"[0]`, "a"},
+		{`"abc"[2]`, "c"},
+		{`"abc"[-1]`, "c"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		testStringObject(t, evaled, tt.want)
+	}
+
+	testErrorObject(t, testEval(`"abc"[3]`), "cooked: 3 out of range")
+}
+
+func testStringObject(t *testing.T, eval object.Object, out string) bool {
+	res, ok := eval.(*object.String)
+	if !ok {
+		t.Errorf("eval not *object.String: got=%T (%+v)", eval, eval)
+		return false
+	}
+	if res.Value != out {
+		t.Errorf("res.Value not equal to %s: got=%s", out, res.Value)
+		return false
+	}
+	return true
+}
+
+func TestSliceExpression(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"[1, 2, 3, 4, 5][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4, 5][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][3:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][:]", "[1, 2, 3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][-2:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][:-3]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][::2]", "[1, 3, 5]"},
+		{"[1, 2, 3, 4, 5][::-1]", "[5, 4, 3, 2, 1]"},
+		{"[1, 2, 3, 4, 5][3:0:-1]", "[4, 3, 2]"},
+		{"[1, 2, 3, 4, 5][-10:10]", "[1, 2, 3, 4, 5]"},
+		{"[1, 2, 3][2:1]", "[]"},
+		{`"hello"[1:4]`, "ell"},
+		{`"hello"[::-1]`, "olleh"},
+		{`"hello"[-3:]`, "llo"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		if evaled == nil || evaled.Inspect() != tt.want {
+			t.Errorf("evaled.Inspect not equal to %s: got=%v", tt.want, evaled)
 		}
 	}
+
+	testErrorObject(t, testEval("[1, 2][::0]"), "cooked: step 0")
+	testErrorObject(t, testEval(`[1, 2]["a":]`), "delulu: ARRAY[STRING]")
+	testErrorObject(t, testEval("1[0:1]"), "delulu: INTEGER")
 }
 
 func TestMapLiteral(t *testing.T) {
@@ -348,23 +479,27 @@ func TestMapLiteral(t *testing.T) {
 		t.Errorf("evaled not *object.Map: got=%T", evaled)
 	}
 
-	want := map[object.Hash]int{
-		(&object.String{Value: "foo"}).Hash(): 1,
-		(&object.Integer{Value: 2}).Hash():    2,
-		TRUE.Hash():                           3,
+	want := []struct {
+		key object.Object
+		val int
+	}{
+		{&object.String{Value: "foo"}, 1},
+		{&object.Integer{Value: 2}, 2},
+		{TRUE, 3},
 	}
 
-	if len(obj.Pairs) != len(want) {
-		t.Errorf("obj.Pairs must be 3 statments: got=%T", len(obj.Pairs))
+	if obj.Len() != len(want) {
+		t.Errorf("obj.Pairs must be 3 statments: got=%d", obj.Len())
 	}
 
-	for key, val := range want {
-		pair, ok := obj.Pairs[key]
+	for _, tt := range want {
+		val, ok := obj.Get(tt.key)
 		if !ok {
-			t.Errorf("no pair for %v", key)
+			t.Errorf("no pair for %v", tt.key.Inspect())
+			continue
 		}
 
-		testIntegerObject(t, pair.Value, val)
+		testIntegerObject(t, val, tt.val)
 	}
 }
 
@@ -379,6 +514,11 @@ func TestMapIndexExpression(t *testing.T) {
 		{`{1: 2}[1]`, 2},
 		{`{fax: 1}[fax]`, 1},
 		{`{cap: 1}[cap]`, 1},
+		{`{[1, 2]: 1}[[1, 2]]`, 1},
+		{`{[1, 2]: 1}[[2, 1]]`, nil},
+		{`{[1, [2, "a"]]: 3}[[1, [2, "a"]]]`, 3},
+		{`{[]: 4}[[]]`, 4},
+		{`{[1]: 1, [1]: 2}[[1]]`, 2},
 	}
 
 	for _, tt := range tests {
@@ -394,7 +534,7 @@ func TestMapIndexExpression(t *testing.T) {
 
 func TestForExpression(t *testing.T) {
 	tests := []struct {
-		got string
+		got  string
 		want int
 	}{
 		{"amogus n = 0; mew (n < 5) { amogus n = n + 1; } n; ", 5},
@@ -405,4 +545,1103 @@ func TestForExpression(t *testing.T) {
 		evaled := testEval(tt.got)
 		testIntegerObject(t, evaled, tt.want)
 	}
-}
\ No newline at end of file
+}
+
+func TestPipeExpression(t *testing.T) {
+	prelude := `
+	amogus add = cook(a, b) { a + b };
+	amogus double = cook(x) { x * 2 };
+	`
+	tests := []struct {
+		got  string
+		want int
+	}{
+		{"5 |> double", 10},
+		{"5 |> add(1)", 6},
+		{"5 |> add(1) |> double |> add(10)", 22},
+		{"2 + 3 |> double", 10},
+		{"[1, 2, 3] |> aura", 3},
+		{`"skibidi" |> aura |> add(b = 1)`, 8},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		testIntegerObject(t, evaled, tt.want)
+	}
+
+	testErrorObject(t, testEval("5 |> 1"), "delulu: INTEGER")
+}
+
+func TestArrowFunction(t *testing.T) {
+	tests := []struct {
+		got  string
+		want int
+	}{
+		{"amogus f = cook(x) => x * 2; f(4)", 8},
+		{"(cook(a, b = 1, ...r) => a + b + aura(r))(1, 2, 3, 4)", 5},
+		{"amogus add = cook(a) => cook(b) => a + b; add(1)(2)", 3},
+		{"[f(1) mew f in [cook(x) => x, cook(x) => -x]][1]", -1},
+		{"5 |> (cook(x) => x + 1)", 6},
+		{"sigma A { cook v() => 5 cook w() => self.v() + 1 }; A().w()", 6},
+		{"amogus g = cook() => slay 1; next(g())", 1},
+		{"holdup (lowkey cook(x) => holdup sleep(0) ?? x)(3)", 3},
+		// the arrow body is a rizz so it runs through the trampoline
+		{"amogus loop = cook(n) => hawk (n == 0) { 0 } tuah { loop(n - 1) }; loop(100000)", 0},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		testIntegerObject(t, evaled, tt.want)
+	}
+
+	evaled := testEval("cook(x = 1) => x * 2")
+	if evaled.Inspect() != "cook(x = 1) => (x * 2)" {
+		t.Errorf("evaled.Inspect not equal to %s: got=%s", "cook(x = 1) => (x * 2)", evaled.Inspect())
+	}
+}
+
+func TestFunctionParameters(t *testing.T) {
+	tests := []struct {
+		got  string
+		want int
+	}{
+		{"amogus f = cook(x, y = 2) { x + y }; f(1);", 3},
+		{"amogus f = cook(x, y = 2) { x + y }; f(1, 5);", 6},
+		{"amogus f = cook(x, y = x * 2) { x + y }; f(3);", 9},
+		{"amogus f = cook(x, y) { x - y }; f(y = 1, x = 5);", 4},
+		{"amogus f = cook(x, y = 2) { x - y }; f(5, y = 3);", 2},
+		{"amogus f = cook(...xs) { aura(xs) }; f();", 0},
+		{"amogus f = cook(x, ...xs) { aura(xs) }; f(1, 2, 3);", 2},
+		{"amogus f = cook(x, y, z) { x + y + z }; f(...[1, 2, 3]);", 6},
+		{"amogus f = cook(x, y, z) { x + y + z }; f(1, ...[2, 3]);", 6},
+		{"aura([0, ...[1, 2], 3]);", 4},
+		// named and positional arguments run in source order
+		{"amogus ch = chan(2); amogus g = cook(v) { send(ch, v); v }; cook(a, b) { a - b }(g(1), b = g(2)); recv(ch) * 10 + recv(ch);", 12},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		testIntegerObject(t, evaled, tt.want)
+	}
+}
+
+func TestFunctionArityErrors(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"cook(x) { x }();", "mid: missing x"},
+		{"cook(x) { x }(1, 2);", "mid: want 1 args, got 2"},
+		{"cook(x) { x }(1, x = 2);", "mid: x given twice"},
+		{"cook(x) { x }(y = 2);", "mid: missing x"},
+		{"cook(x = 1) { x }(y = 2);", "mid: unknown y"},
+		{"cook(x) { x }(x = 1, x = 2);", "mid: x given twice"},
+		{"cook(x) { x }(...1);", "delulu: ...INTEGER"},
+		{"amogus g = ...[1, 2]; g + 1;", "delulu: ... outside call or array"},
+		{"yap(1 + ...[1]);", "delulu: ... outside call or array"},
+		{`aura(x = "foo");`, "mid: unknown x"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		err, ok := evaled.(*object.Error)
+		if !ok {
+			t.Errorf("evaled not *object.Error: got=%T", evaled)
+			continue
+		}
+
+		if err.Message != tt.want {
+			t.Errorf("err.Message not equal to %s: got=%s", tt.want, err.Message)
+		}
+	}
+}
+
+func TestMapInsertionOrder(t *testing.T) {
+	got := `{"z": 1, "a": 2, 3: 4, [1]: 5, "z": 6}`
+	want := "{z: 6,a: 2,3: 4,[1]: 5}"
+
+	// go map iteration is random, repeat to catch it
+	for i := 0; i < 20; i++ {
+		evaled := testEval(got)
+		if evaled.Inspect() != want {
+			t.Fatalf("evaled.Inspect not equal to %s: got=%s", want, evaled.Inspect())
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		got  string
+		want any
+	}{
+		{"{3, 1, 3, 2}", "{3, 1, 2}"},
+		{"set()", "set()"},
+		{"set([1, 1, 2])", "{1, 2}"},
+		{`set("hello")`, "{h, e, l, o}"},
+		{"{[1], [1]}", "{[1]}"},
+		{"2 in {1, 2}", true},
+		{"3 in {1, 2}", false},
+		{`"a" in {"a": 1}`, true},
+		{"[1] in [[1], 2]", true},
+		{`"ell" in "hello"`, true},
+		{"{1, 2} == {2, 1}", true},
+		{"{1, 2} == {1}", false},
+		{"union({1, 2}, {2, 3})", "{1, 2, 3}"},
+		{"intersect({1, 2, 3}, {3, 2})", "{2, 3}"},
+		{"diff({1, 2, 3}, {2})", "{1, 3}"},
+		{"{1}.union({2})", "{1, 2}"},
+		{"{1, 2}.diff({1}).len()", 1},
+		{"amogus s = {1}; s.add(2); s", "{1}"},
+		{"{1}.add(2).has(2)", true},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case bool:
+			testBooleanObject(t, evaled, want)
+		case string:
+			if evaled == nil || evaled.Inspect() != want {
+				t.Errorf("evaled.Inspect not equal to %s: got=%v", want, evaled)
+			}
+		}
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"{{1}};", "delulu: SET"},
+		{"{1} in {2};", "delulu: SET"},
+		{"1 in 2;", "delulu: INTEGER in INTEGER"},
+		{`1 in "a";`, "touch grass: INTEGER in STRING"},
+		{"union({1}, [1]);", "got mogged: ARRAY"},
+		{"diff({1});", "mid: want 2 args, got 1"},
+		{"set(1);", "got mogged: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		testErrorObject(t, evaled, tt.want)
+	}
+}
+
+func TestForEachExpression(t *testing.T) {
+	tests := []struct {
+		got  string
+		want any
+	}{
+		{"amogus s = 0; mew (x in [1, 2, 3]) { amogus s = s + x; }; s", 6},
+		{`amogus s = ""; mew (k in {"a": 1, "b": 2}) { amogus s = s + k; }; s`, "ab"},
+		{"amogus s = 0; mew (x in {4, 4, 5}) { amogus s = s + x; }; s", 9},
+		{`amogus s = ""; mew (c in "abc") { amogus s = c + s; }; s`, "cba"},
+		{"amogus f = cook(xs) { mew (x in xs) { hawk (x > 1) { rizz x; } } }; f([1, 5, 7])", 5},
+		{"mew (x in []) { x }", nil},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case string:
+			testStringObject(t, evaled, want)
+		default:
+			testNullObject(t, evaled)
+		}
+	}
+
+	evaled := testEval("mew (x in 1) {}")
+	testErrorObject(t, evaled, "got mogged: INTEGER")
+}
+
+func TestComprehension(t *testing.T) {
+	prelude := "amogus xs = [1, 2, 3]; nocap x = 100;"
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"[x * 2 mew x in xs hawk x > 1]", "[4, 6]"},
+		{"[x mew x in []]", "[]"},
+		{`[c + c mew c in "ab"]`, "[aa, bb]"},
+		{`[k mew k in {"a": 1, "b": 2}]`, "[a, b]"},
+		{"{x & 1 mew x in xs}", "{1, 0}"},
+		{`{x: x * x mew x in xs hawk x != 2}`, "{1: 1,3: 9}"},
+		{"[[y mew y in xs hawk y < x] mew x in xs]", "[[], [1], [1, 2]]"},
+		// the variable does not leak or clash with the nocap outside
+		{"[x mew x in xs]; x", "100"},
+		// closures keep the value of their own step
+		{"[f() mew f in [cook() { x } mew x in xs]]", "[1, 2, 3]"},
+		// pending tail calls run before they are stored
+		{"amogus id = cook(v) { v }; [hawk (fax) { rizz id(x) } mew x in xs]", "[1, 2, 3]"},
+		{"amogus id = cook(v) { v }; {hawk (fax) { rizz id(x) }: x mew x in xs hawk hawk (fax) { rizz id(x > 2) }}", "{3: 3}"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		if evaled == nil || evaled.Inspect() != tt.want {
+			t.Errorf("evaled.Inspect not equal to %s: got=%v", tt.want, evaled)
+		}
+	}
+
+	errors := []struct {
+		got  string
+		want string
+	}{
+		{"[x mew x in 5]", "got mogged: INTEGER"},
+		{"[y mew x in [1]]", "delulu: y"},
+		{"[x mew x in [1] hawk y]", "delulu: y"},
+		{"{cook() {}: 1 mew x in [1]}", "delulu: FUNCTION"},
+	}
+
+	for _, tt := range errors {
+		evaled := testEval(tt.got)
+		testErrorObject(t, evaled, tt.want)
+	}
+}
+
+func TestOptionalAndCoalesceExpression(t *testing.T) {
+	prelude := `squad P { x }; amogus d = {"user": {"name": "rex", "tags": [1, 2]}, "p": P(3)}; `
+	tests := []struct {
+		got  string
+		want any
+	}{
+		{`d["user"]?.["name"]`, "rex"},
+		{`d["nope"]?.["name"]`, nil},
+		{`d["nope"]?.["name"]?.[0]`, nil},
+		{`d["user"]?.["tags"]?.[1:]`, "[2]"},
+		{`d["p"]?.x`, 3},
+		{`d["q"]?.x`, nil},
+		{`d["nope"]?.["name"] ?? "anon"`, "anon"},
+		// a null at ?. skips the rest of the chain
+		{`d["nope"]?.["name"]["first"]`, nil},
+		{`d["nope"]?.x.y`, nil},
+		{`d["nope"]?.["tags"][0:1].len`, nil},
+		// fields of a map are its string keys
+		{`d.user.name`, "rex"},
+		{`d?.user?.tags[0]`, 1},
+		{`d.nope?.name`, nil},
+		{`{"a": 1}?.a`, 1},
+		{`{"keys": 1}.keys()`, "[keys]"},
+		{`d["user"]["name"] ?? "anon"`, "rex"},
+		{"0 ?? 1", 0},
+		{"cap ?? fax", false},
+		{`1 ?? missing`, 1},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case bool:
+			testBooleanObject(t, evaled, want)
+		case string:
+			if evaled == nil || evaled.Inspect() != want {
+				t.Errorf("evaled.Inspect not equal to %s: got=%v", want, evaled)
+			}
+		default:
+			testNullObject(t, evaled)
+		}
+	}
+
+	// without ?. indexing null still fails
+	evaled := testEval(prelude + `d["nope"]["name"]?.["first"]`)
+	testErrorObject(t, evaled, "delulu: NULL")
+	evaled = testEval(prelude + `d.nope.name`)
+	testErrorObject(t, evaled, "delulu: NULL.name")
+}
+
+func TestGenerator(t *testing.T) {
+	prelude := `
+	amogus count = cook(n) { amogus i = 0; mew (i < n) { slay i; amogus i = i + 1; } };
+	amogus nat = cook() { amogus i = 0; mew (fax) { slay i; amogus i = i + 1; } };
+	`
+	tests := []struct {
+		got  string
+		want any
+	}{
+		{"count(2)", "generator"},
+		{"amogus g = count(2); [next(g), next(g), next(g), next(g, -1)]", "[0, 1, null, -1]"},
+		{"amogus s = 0; mew (x in count(4)) { amogus s = s + x; }; s", 6},
+		{"set(count(3))", "{0, 1, 2}"},
+		{"amogus f = cook() { mew (x in nat()) { hawk (x > 2) { rizz x; } } }; f()", 3},
+		{"amogus g = nat(); next(g); next(g); next(g)", 2},
+		{"amogus once = cook() { slay 1; rizz 5; slay 2; }; set(once())", "{1}"},
+		{`amogus bad = cook() { slay 1; yeet "oops"; }; amogus g = bad(); next(g); next(g)`, "yeet: oops [E009]"},
+		{`amogus bad = cook() { slay 1; yeet "oops"; }; sus { mew (x in bad()) {} } cope (e) { e }`, "oops"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case string:
+			if evaled == nil || evaled.Inspect() != want {
+				t.Errorf("evaled.Inspect not equal to %s: got=%v", want, evaled)
+			}
+		}
+	}
+
+	evaled := testEval("amogus g = 0; amogus f = cook() { slay next(g); }; amogus g = f(); next(g)")
+	testErrorObject(t, evaled, "delulu: generator already running")
+	evaled = testEval("next([1])")
+	testErrorObject(t, evaled, "got mogged: ARRAY")
+}
+
+func TestGeneratorCleanup(t *testing.T) {
+	prelude := "amogus nat = cook() { amogus i = 0; mew (fax) { slay i; amogus i = i + 1; } }; "
+	tests := []string{
+		// loop left early stops the body right away
+		"amogus f = cook() { mew (x in nat()) { hawk (x > 2) { rizz x; } } }; f()",
+		// abandoned generators are stopped once collected
+		"amogus f = cook() { amogus g = nat(); next(g); next(g) }; f()",
+	}
+
+	for _, tt := range tests {
+		before := runtime.NumGoroutine()
+		testEval(prelude + tt)
+
+		for i := 0; runtime.NumGoroutine() > before; i++ {
+			if i == 100 {
+				t.Fatalf("generator goroutine leaked for %s", tt)
+			}
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestTailCall(t *testing.T) {
+	// deep recursion would blow this stack without the trampoline
+	defer debug.SetMaxStack(debug.SetMaxStack(16 << 20))
+
+	prelude := `
+	amogus loop = cook(n, acc) { hawk (n == 0) { rizz acc; } rizz loop(n - 1, acc + 1); };
+	amogus even = cook(n) { hawk (n == 0) { rizz fax; } rizz odd(n - 1); };
+	amogus odd = cook(n) { hawk (n == 0) { rizz cap; } rizz even(n - 1); };
+	`
+	tests := []struct {
+		got  string
+		want any
+	}{
+		{"loop(200000, 0)", 200000},
+		{"even(200001)", false},
+		{"rizz loop(3, 0);", 3},
+		{`amogus f = cook() { rizz aura("abc"); }; f()`, 3},
+		{`amogus f = cook() { sus { rizz yap(1, 2); } cope (e) { rizz e.code; } }; f()`, "E006"},
+		{`amogus f = cook() { sus { rizz loop(2, 0); } periodt { yeet "late"; } }; sus { f() } cope (e) { e }`, "late"},
+		// stored values run the call instead of keeping it pending
+		{"amogus x = hawk (fax) { rizz loop(2, 0) }; [x]", "[2]"},
+		{"[hawk (fax) { rizz loop(2, 0) }]", "[2]"},
+		{`{"a": hawk (fax) { rizz loop(2, 0) }}`, "{a: 2}"},
+		{"sigma B {}; amogus b = B(); b.n = hawk (fax) { rizz loop(2, 0) }; b", "B{n: 2}"},
+		{"{hawk (fax) { rizz loop(2, 0) }, 1}", "{2, 1}"},
+		{"{hawk (fax) { rizz loop(2, 0) }: 1}", "{2: 1}"},
+		{"amogus x = hawk (fax) { rizz loop(2, 0) }; x + 1", 3},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case bool:
+			testBooleanObject(t, evaled, want)
+		case string:
+			if _, ok := evaled.(*object.String); ok {
+				testStringObject(t, evaled, want)
+			} else if evaled == nil || evaled.Inspect() != want {
+				t.Errorf("evaled.Inspect not equal to %s: got=%v", want, evaled)
+			}
+		}
+	}
+}
+
+func TestSpawnAndChannels(t *testing.T) {
+	prelude := `
+	amogus produce = cook(ch, n) { amogus i = 0; mew (i < n) { send(ch, i); amogus i = i + 1; }; close(ch); "done" };
+	amogus fib = cook(n) { hawk (n < 2) { rizz n; } fib(n - 1) + fib(n - 2) };
+	`
+	tests := []struct {
+		got  string
+		want any
+	}{
+		{"sendit fib(1)", "task"},
+		{"join(sendit fib(15))", 610},
+		{"amogus ts = [sendit fib(10), sendit fib(11)]; join(ts[0]) + join(ts[1])", 144},
+		{"amogus ch = chan(); amogus t = sendit produce(ch, 4); amogus s = 0; mew (v in ch) { amogus s = s + v; }; s", 6},
+		{"amogus ch = chan(); amogus t = sendit produce(ch, 1); recv(ch); recv(ch)", nil},
+		{"amogus ch = chan(2); send(ch, 1); send(ch, 2); recv(ch) + recv(ch)", 3},
+		{"amogus a = chan(1); amogus b = chan(1); send(b, 7); select([a, b])", "[1, 7]"},
+		{"amogus a = chan(); close(a); select([a])", "[0, null]"},
+		// tasks read a closure scope the main task keeps declaring into
+		{`amogus x = 0;
+		amogus read = cook() { amogus i = 0; mew (i < 100) { x; amogus i = i + 1; }; x };
+		amogus ts = [sendit read(), sendit read()];
+		amogus j = 0; mew (j < 100) { amogus x = j; amogus j = j + 1; };
+		join(ts[0]); join(ts[1]); x`, 99},
+		// tasks hash the same key of a shared map at once
+		{`amogus m = {"k": 1, [1, 2]: 2}; amogus k = "k"; amogus a = [1, 2];
+		amogus get = cook() { amogus i = 0; mew (i < 50) { m[k]; m[a]; amogus i = i + 1; }; m[k] + m[a] };
+		amogus ts = [sendit get(), sendit get(), sendit get(), sendit get()];
+		join(ts[0]) + join(ts[1]) + join(ts[2]) + join(ts[3])`, 12},
+		// tasks write and read fields of the same instance and record
+		{`sigma B {}; squad P { n }; amogus b = B(); amogus p = P(0);
+		amogus put = cook(v) { amogus i = 0; mew (i < 50) { b.n = v; p.n = v; b.n; p; amogus i = i + 1; }; v };
+		amogus ts = [sendit put(1), sendit put(2), sendit put(3)];
+		join(ts[0]) + join(ts[1]) + join(ts[2]) + (b.n - p.n) * 0`, 6},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case string:
+			if evaled == nil || evaled.Inspect() != want {
+				t.Errorf("evaled.Inspect not equal to %s: got=%v", want, evaled)
+			}
+		default:
+			testNullObject(t, evaled)
+		}
+	}
+}
+
+func TestSpawnAndChannelErrors(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"join(sendit yap(1, 2));", "cant yap: 2"},
+		{"sendit nope();", "delulu: nope"},
+		{"amogus ch = chan(); close(ch); send(ch, 1);", "delulu: send on closed chan"},
+		{"amogus ch = chan(); close(ch); close(ch);", "delulu: chan already closed"},
+		{"chan(-1);", "cooked: -1 out of range"},
+		{"select([]);", "mid: select needs a chan"},
+		{"select([1]);", "got mogged: INTEGER"},
+		{"join(1);", "got mogged: INTEGER"},
+		{"recv(1);", "got mogged: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		testErrorObject(t, evaled, tt.want)
+	}
+}
+
+func TestAsyncAwait(t *testing.T) {
+	eval.SetClock(eval.NewFakeClock())
+	defer eval.SetClock(eval.NewFakeClock())
+
+	prelude := `
+	amogus fetch = lowkey cook(x, ms) { holdup sleep(ms); x * 2 };
+	amogus log = chan(3);
+	amogus push = cook(v) { send(log, v) };
+	`
+	tests := []struct {
+		got  string
+		want any
+	}{
+		{"fetch(1, 10)", "promise(pending)"},
+		{"holdup fetch(21, 10)", 42},
+		{"holdup 5", 5},
+		{"amogus t = now(); holdup sleep(50); now() - t", 50},
+		// both sleeps run at once so only the longest counts
+		{"amogus t = now(); amogus a = fetch(1, 30); amogus b = fetch(2, 10); holdup a + holdup b + now() - t", 36},
+		{"amogus m = lowkey cook() { [holdup fetch(1, 30), holdup fetch(2, 10)] }; holdup m()", "[2, 4]"},
+		{"amogus f = lowkey cook() { rizz fetch(3, 1) }; holdup f()", 6},
+		{"holdup lowkey cook() { holdup lowkey cook() { 7 }() }()", 7},
+		// a body runs synchronously up to its first pending holdup
+		{"amogus f = lowkey cook() { push(1); holdup sleep(1); push(3) }; amogus p = f(); push(2); holdup p; [recv(log), recv(log), recv(log)]", "[1, 2, 3]"},
+		{"setTimeout(cook() { push(2) }, 20); setTimeout(cook() { push(1) }, 10); holdup sleep(30); [recv(log), recv(log)]", "[1, 2]"},
+		{`amogus bad = lowkey cook() { holdup sleep(1); yeet "boom" };
+		amogus m = lowkey cook() { sus { holdup bad() } cope (e) { "caught " + e } };
+		holdup m()`, "caught boom"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case string:
+			if evaled == nil || evaled.Inspect() != want {
+				t.Errorf("evaled.Inspect not equal to %s: got=%v", want, evaled)
+			}
+		}
+		if err := eval.RunLoop(); err != nil {
+			t.Errorf("eval.RunLoop must be nil for %s: got=%s", tt.got, err.Inspect())
+		}
+	}
+}
+
+func TestAsyncErrors(t *testing.T) {
+	eval.SetClock(eval.NewFakeClock())
+	defer eval.SetClock(eval.NewFakeClock())
+
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{`holdup lowkey cook() { yeet "boom" }();`, "yeet: boom"},
+		{"sleep(-1);", "cooked: -1 out of range"},
+		{`sleep("1");`, "got mogged: STRING"},
+		{"setTimeout(cook() {});", "mid: want 2 args, got 1"},
+		{"now(1);", "mid: want 0 args, got 1"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		testErrorObject(t, evaled, tt.want)
+		eval.RunLoop()
+	}
+
+	// errors nobody awaited are reported once the loop is done
+	loop := []struct {
+		got  string
+		want string
+	}{
+		{`amogus bad = lowkey cook() { yeet "boom" }; bad();`, "yeet: boom"},
+		{"setTimeout(cook() { yap(1, 2) }, 5);", "cant yap: 2"},
+	}
+
+	for _, tt := range loop {
+		testEval(tt.got)
+		testErrorObject(t, eval.RunLoop(), tt.want)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	prelude := "squad Point { x, y }; amogus p = Point(1, 2); "
+	tests := []struct {
+		got  string
+		want any
+	}{
+		{"p", "Point{x: 1, y: 2}"},
+		{"Point", "squad Point {x, y}"},
+		{"p.x + p.y", 3},
+		{"p.x = 10; p.x", 10},
+		{"amogus q = p; q.y = 5; p.y", 5},
+		{"Point(y = 3, x = 4)", "Point{x: 4, y: 3}"},
+		{"Point(1, y = 3)", "Point{x: 1, y: 3}"},
+		{"p == Point(1, 2)", true},
+		{"p == Point(2, 1)", false},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case bool:
+			testBooleanObject(t, evaled, want)
+		case string:
+			if evaled == nil || evaled.Inspect() != want {
+				t.Errorf("evaled.Inspect not equal to %s: got=%v", want, evaled)
+			}
+		}
+	}
+}
+
+func TestRecordErrors(t *testing.T) {
+	prelude := "squad Point { x, y }; amogus p = Point(1, 2); "
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"p.z", "delulu: Point.z"},
+		{"p.z = 1;", "delulu: Point.z"},
+		{"Point(1);", "mid: missing y"},
+		{"Point(1, 2, 3);", "mid: want 2 args, got 3"},
+		{"Point(1, z = 2);", "delulu: Point.z"},
+		{"Point(1, x = 2);", "mid: x given twice"},
+		{"1.x;", "delulu: INTEGER.x"},
+		{"amogus n = 1; n.x = 2;", "delulu: INTEGER.x"},
+		{"{p: 1};", "delulu: RECORD"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		testErrorObject(t, evaled, tt.want)
+	}
+}
+
+func TestClass(t *testing.T) {
+	prelude := `
+	sigma Animal {
+		cook init(name) { self.name = name; }
+		cook speak() { rizz self.name + " speaks"; }
+		cook rename(name) { self.name = name; self }
+	}
+	sigma Dog < Animal {
+		cook init(name, breed = "mutt") { super.init(name); self.breed = breed; }
+		cook speak() { rizz super.speak() + " woof"; }
+	}
+	sigma Puppy < Dog {
+		cook speak() { rizz super.speak() + "!"; }
+	}
+	`
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{`Animal("cat").speak()`, "cat speaks"},
+		{`Dog("rex").speak()`, "rex speaks woof"},
+		{`Puppy("bit").speak()`, "bit speaks woof!"},
+		{`Puppy("bit").breed`, "mutt"},
+		{`Dog("rex", breed = "pug").breed`, "pug"},
+		{`Animal("a").rename("b").speak()`, "b speaks"},
+		{`amogus speak = Dog("rex").speak; speak()`, "rex speaks woof"},
+		{`amogus d = Dog("rex"); d.name = "max"; d.speak()`, "max speaks woof"},
+		{`Dog("rex")`, "Dog{breed: mutt, name: rex}"},
+		{`Animal`, "sigma Animal"},
+		{`sigma Empty {}; Empty()`, "Empty{}"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		if evaled == nil || evaled.Inspect() != tt.want {
+			t.Errorf("evaled.Inspect not equal to %s: got=%v", tt.want, evaled)
+		}
+	}
+}
+
+func TestClassErrors(t *testing.T) {
+	prelude := `
+	sigma Animal {
+		cook init(name) { self.name = name; }
+		cook oops() { super.init(); }
+	}
+	`
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{`Animal();`, "mid: missing name"},
+		{`Animal("a").fly;`, "delulu: Animal.fly"},
+		{`Animal("a").oops();`, "delulu: super"},
+		{`sigma Dog < Cat {};`, "delulu: Cat"},
+		{`sigma Dog < Animal { cook f() { super.g() } }; Dog("a").f();`, "delulu: Animal.g"},
+		{`amogus n = 1; sigma Dog < n {};`, "delulu: < INTEGER"},
+		{`sigma Empty {}; Empty(1);`, "mid: want 0 args, got 1"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		testErrorObject(t, evaled, tt.want)
+	}
+}
+
+func TestOperatorMethods(t *testing.T) {
+	prelude := `
+	sigma Vec {
+		cook init(x, y) { self.x = x; self.y = y; }
+		cook +(o) => Vec(self.x + o.x, self.y + o.y)
+		cook *(k) => Vec(self.x * k, self.y * k)
+		cook ==(o) => [self.x, self.y] == [o.x, o.y]
+		cook <(o) => self.x < o.x
+		cook [](i) => hawk (i == 0) { self.x } tuah { self.y }
+		cook inspect() => [self.x, self.y]
+	}
+	sigma Money {
+		cook init(cents) { self.cents = cents; }
+		cook inspect() => "money"
+	}
+	sigma Vec3 < Vec {}
+	`
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"Vec(1, 2) + Vec(3, 4)", "[4, 6]"},
+		{"Vec(1, 2) * 3", "[3, 6]"},
+		{"Vec(1, 2) + Vec(1, 1) * 2", "[3, 4]"},
+		{"Vec(1, 2) == Vec(1, 2)", "true"},
+		{"Vec(1, 2) != Vec(1, 2)", "false"},
+		{"Vec(1, 3) != Vec(1, 2)", "true"},
+		{"Vec(1, 2) < Vec(3, 0)", "true"},
+		{"Vec(1, 2)[1]", "2"},
+		{"[Vec(1, 1), Money(5)]", "[[1, 1], money]"},
+		{"Vec3(1, 2) + Vec(1, 1)", "[2, 3]"},
+		// without a handler instances keep comparing by identity
+		{"Money(1) == Money(1)", "false"},
+		{"amogus m = Money(1); m == m", "true"},
+		// an inspect that prints itself again falls back
+		{"sigma V { cook inspect() => self }; V()", "V{}"},
+		{"sigma V { cook init() { self.n = 1; } cook inspect() => [self] }; V()", "[V{n: 1}]"},
+		{"sigma A { cook inspect() => B(self) }; sigma B { cook init(a) { self.a = a; } cook inspect() => self.a }; A()", "A{}"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(prelude + tt.got)
+		if evaled == nil || evaled.Inspect() != tt.want {
+			t.Errorf("evaled.Inspect not equal to %s: got=%v", tt.want, evaled)
+		}
+	}
+
+	errors := []struct {
+		got  string
+		want string
+	}{
+		{"Vec(1, 2) - Vec(1, 2);", "delulu: INSTANCE - INSTANCE"},
+		{"2 * Vec(1, 2);", "touch grass: INTEGER * INSTANCE"},
+		{"Money(1) + 1;", "touch grass: INSTANCE + INTEGER"},
+		{"Money(1)[0];", "delulu: INSTANCE"},
+		{"Vec(1, 2) + 1;", "delulu: INTEGER.x"},
+		{`sigma B { cook +(o) { yeet "nope" } }; B() + 1;`, "yeet: nope"},
+	}
+
+	for _, tt := range errors {
+		evaled := testEval(prelude + tt.got)
+		testErrorObject(t, evaled, tt.want)
+	}
+}
+
+func TestBuiltinMethods(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{`"hello".len()`, "5"},
+		{`"Hello".upper()`, "HELLO"},
+		{`"Hello".lower()`, "hello"},
+		{`"a,b,c".split(",")`, "[a, b, c]"},
+		{`"hello".contains("ell")`, "true"},
+		{`[1, 2].len()`, "2"},
+		{`[1, 2].push(3)`, "[1, 2, 3]"},
+		{`amogus a = [1]; a.push(2); a`, "[1]"},
+		{`[1, 2].first()`, "1"},
+		{`[1, 2].last()`, "2"},
+		{`[].first()`, "null"},
+		{`[1, [2]].contains([2])`, "true"},
+		{`{"a": 1, "b": 2}.len()`, "2"},
+		{`{"b": 1, "a": 2}.keys()`, "[b, a]"},
+		{`{"b": 1, "a": 2}.values()`, "[1, 2]"},
+		{`{[1]: 1}.has([1])`, "true"},
+		{`{"a": 1}.has("b")`, "false"},
+		{`amogus up = "hi".upper; up()`, "HI"},
+		{`[3, 4].push(5).len()`, "3"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		if evaled == nil || evaled.Inspect() != tt.want {
+			t.Errorf("evaled.Inspect not equal to %s: got=%v", tt.want, evaled)
+		}
+	}
+}
+
+func TestBuiltinMethodErrors(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{`"a".nope();`, "delulu: STRING.nope"},
+		{`1.len();`, "delulu: INTEGER.len"},
+		{`[].push();`, "mid: want 1 args, got 0"},
+		{`"a".len(1);`, "mid: want 0 args, got 1"},
+		{`"a".split(1);`, "got mogged: INTEGER"},
+		{`{}.has({});`, "delulu: MAP"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		testErrorObject(t, evaled, tt.want)
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{`sus { yeet "boom"; } cope (e) { e }`, "boom"},
+		{`sus { yeet [1, 2]; } cope (e) { e[1] }`, "2"},
+		{`sus { 1 } cope (e) { 2 }`, "1"},
+		{`sus { foo } cope { 2 }`, "2"},
+		{`sus { 1 + fax } cope (e) { e.message }`, "touch grass: INTEGER + BOOLEAN"},
+		{`sus { 1 + fax } cope (e) { e.kind }`, "type_mismatch"},
+		{`sus { 1 + fax } cope (e) { e.code }`, "E001"},
+		{`sus { [1][5] } cope (e) { e }`, "Error{message: cooked: 5 out of range, kind: index_out_of_range, code: E004}"},
+		{`sus { sus { foo } cope (e) { yeet e } } cope (e) { e.kind }`, "unknown_identifier"},
+		{`sus { sus { foo } cope (e) { yeet e } } cope (e) { e.message }`, "delulu: foo"},
+		{`amogus f = cook() { yeet "deep" }; sus { f() } cope (e) { e }`, "deep"},
+		{`amogus n = 0; sus { 1 } periodt { amogus n = 1 }; n`, "1"},
+		{`amogus n = 0; sus { yeet 1 } cope { 2 } periodt { amogus n = 1 }; n`, "1"},
+		{`amogus f = cook() { sus { rizz 1 } periodt { amogus x = 2 } }; f()`, "1"},
+		{`amogus f = cook() { sus { rizz 1 } periodt { rizz 2 } }; f()`, "2"},
+		{`amogus f = cook() { sus { yeet 1 } cope { rizz 2 }; 3 }; f()`, "2"},
+		// the catch parameter shadows outer names only inside cope
+		{`amogus e = 5; sus { yeet 1 } cope (e) { e }; e`, "5"},
+		{`nocap e = 5; sus { yeet 1 } cope (e) { e }`, "1"},
+		{`sus { yeet 1 } cope (e) { e }; e`, "delulu: e [E002]"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		if evaled == nil || evaled.Inspect() != tt.want {
+			t.Errorf("evaled.Inspect not equal to %s: got=%v", tt.want, evaled)
+		}
+	}
+}
+
+func TestUncaughtErrors(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+		kind string
+	}{
+		{`yeet "boom";`, "yeet: boom", "E009"},
+		{`sus { yeet 1 } periodt { 2 };`, "yeet: 1", "E009"},
+		{`sus { 1 } periodt { yeet 2 };`, "yeet: 2", "E009"},
+		{`sus { yeet 1 } cope { yeet 2 };`, "yeet: 2", "E009"},
+		{`sus { yeet 1 } cope { foo };`, "delulu: foo", "E002"},
+		{`aura(1, 2);`, "zero aura: 2", "E006"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		if !testErrorObject(t, evaled, tt.want) {
+			continue
+		}
+		if code := evaled.(*object.Error).Kind.Code; code != tt.kind {
+			t.Errorf("err.Kind.Code not equal to %s: got=%s", tt.kind, code)
+		}
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		got  string
+		want object.ErrorKind
+	}{
+		{"1 + fax;", object.TYPE_MISMATCH},
+		{"-fax;", object.TYPE_MISMATCH},
+		{`aura(1);`, object.TYPE_MISMATCH},
+		{"foobar;", object.UNKNOWN_IDENTIFIER},
+		{"fax + cap;", object.BAD_OPERATOR},
+		{`"a" - "b";`, object.BAD_OPERATOR},
+		{"[1][1];", object.INDEX_OUT_OF_RANGE},
+		{`"a"[-2];`, object.INDEX_OUT_OF_RANGE},
+		{"1();", object.NOT_CALLABLE},
+		{"cook(x) { x }();", object.ARITY},
+		{"aura();", object.ARITY},
+		{"squad P { x }; P(1).y;", object.UNKNOWN_MEMBER},
+		{`"a".nope();`, object.UNKNOWN_MEMBER},
+		{"{{}: 1};", object.UNHASHABLE},
+		{"yeet 1;", object.THROWN},
+		{"1 >> -1;", object.BAD_ARGUMENT},
+		{"chan(-1);", object.BAD_ARGUMENT},
+		{"sleep(-1);", object.BAD_ARGUMENT},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		err, ok := evaled.(*object.Error)
+		if !ok {
+			t.Errorf("evaled not *object.Error for %s: got=%T", tt.got, evaled)
+			continue
+		}
+		if err.Kind != tt.want {
+			t.Errorf("err.Kind not equal to %s for %s: got=%s", tt.want.Name, tt.got, err.Kind.Name)
+		}
+	}
+}
+
+func TestConstStatement(t *testing.T) {
+	tests := []struct {
+		got  string
+		want any
+	}{
+		{"nocap x = 1; x", 1},
+		{"nocap x = 1; amogus f = cook() { amogus x = 2; x }; f() + x", 3},
+		{"amogus n = 0; mew (n < 3) { nocap y = n; amogus n = n + 1; } y", 2},
+		{"amogus f = cook() { amogus yap = 2; yap }; f()", 2},
+		// builtins are not constants
+		{"amogus next = 1; amogus set = 2; next + set", 3},
+		{"squad aura { a }; aura(4).a", 4},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := parser.NewParser(l)
+		program := p.Parse()
+		if len(p.Errors()) > 0 {
+			t.Errorf("parser errors for %s: got=%v", tt.got, p.Errors())
+			continue
+		}
+
+		evaled := eval.Eval(program, object.NewEnvironment())
+		switch want := tt.want.(type) {
+		case int:
+			testIntegerObject(t, evaled, want)
+		case string:
+			testErrorObject(t, evaled, want)
+		}
+	}
+}
+
+// parser only sees one input, redeclaring across
+// inputs like the repl does is caught at runtime
+func TestConstRedeclaredAcrossInputs(t *testing.T) {
+	env := object.NewEnvironment()
+	for i, input := range []string{"nocap x = 1;", "amogus x = 2;"} {
+		l := lexer.NewLexer(input)
+		p := parser.NewParser(l)
+		evaled := eval.Eval(p.Parse(), env)
+
+		if i == 0 && evaled != nil {
+			t.Fatalf("evaled not nil: got=%s", evaled.Inspect())
+		}
+		if i == 1 {
+			testErrorObject(t, evaled, "nocap: x")
+		}
+	}
+
+	val, _ := env.Get("x")
+	testIntegerObject(t, val, 1)
+}
+
+func testParseProgram(got string) *ast.Program {
+	l := lexer.NewLexer(got)
+	p := parser.NewParser(l)
+	return p.Parse()
+}
+
+func TestQuoteUnquote(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"quote(5)", "5"},
+		{"quote(5 + 8)", "(5 + 8)"},
+		{"quote(foobar + barfoo)", "(foobar + barfoo)"},
+		{"quote(unquote(4 + 4))", "8"},
+		{"quote(8 + unquote(4 + 4))", "(8 + 8)"},
+		{"quote(unquote(fax == cap))", "cap"},
+		{`quote(unquote("a" + "b") + x)`, "(ab + x)"},
+		{"amogus q = quote(4 + 4); quote(unquote(4 + 4) + unquote(q))", "(8 + (4 + 4))"},
+		{"amogus f = cook() { rizz quote(x); }; f()", "x"},
+		{"amogus f = cook(x) { quote(unquote(x) + 1) }; f(1); f(2)", "(2 + 1)"},
+	}
+
+	for _, tt := range tests {
+		evaled := testEval(tt.got)
+		quote, ok := evaled.(*object.Quote)
+		if !ok {
+			t.Errorf("evaled not *object.Quote: got=%T (%+v)", evaled, evaled)
+			continue
+		}
+		if quote.Node.String() != tt.want {
+			t.Errorf("quote.Node.String not equal to %s: got=%s", tt.want, quote.Node.String())
+		}
+	}
+
+	evaled := testEval("quote(unquote([1]))")
+	testErrorObject(t, evaled, "got mogged: unquote ARRAY")
+}
+
+func TestDefineMacros(t *testing.T) {
+	got := `
+	amogus number = 1;
+	amogus function = cook(x, y) { x + y };
+	amogus mymacro = cheat(x, y) { x + y; };
+	`
+	env := object.NewEnvironment()
+	program := testParseProgram(got)
+	eval.DefineMacros(program, env)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements must be 2 statements: got=%d", len(program.Statements))
+	}
+	for _, name := range []string{"number", "function"} {
+		if _, ok := env.Get(name); ok {
+			t.Errorf("%s must not be defined", name)
+		}
+	}
+
+	obj, ok := env.Get("mymacro")
+	if !ok {
+		t.Fatalf("mymacro not in env")
+	}
+	macro, ok := obj.(*object.Macro)
+	if !ok {
+		t.Fatalf("obj not *object.Macro: got=%T", obj)
+	}
+	if len(macro.Parameters) != 2 || macro.Body.String() != "(x + y)" {
+		t.Errorf("macro not equal to cheat(x, y) {(x + y)}: got=%s", macro.Inspect())
+	}
+}
+
+func TestExpandMacros(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{
+			"amogus infix = cheat() { quote(1 + 2); }; infix();",
+			"(1 + 2)",
+		},
+		{
+			"amogus reverse = cheat(a, b) { quote(unquote(b) - unquote(a)); }; reverse(2 + 2, 10 - 5);",
+			"(10 - 5) - (2 + 2)",
+		},
+		{
+			`amogus unless = cheat(cond, cons, alt) {
+				quote(hawk (!(unquote(cond))) { unquote(cons); } tuah { unquote(alt); });
+			};
+			unless(10 > 5, 1, 2);`,
+			"hawk (!(10 > 5)) { 1 } tuah { 2 }",
+		},
+		{
+			`amogus unless = cheat(c, a, b) { quote(hawk (!(unquote(c))) { unquote(a) } tuah { unquote(b) }) };
+			amogus r1 = unless(1 > 2, "a", "b");
+			amogus r2 = unless(1 < 2, "c", "d");`,
+			`amogus r1 = hawk (!(1 > 2)) { "a" } tuah { "b" };
+			amogus r2 = hawk (!(1 < 2)) { "c" } tuah { "d" };`,
+		},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		program := testParseProgram(tt.got)
+		eval.DefineMacros(program, env)
+		expanded, err := eval.ExpandMacros(program, env)
+		if err != nil {
+			t.Fatalf("eval.ExpandMacros failed: %s", err.Inspect())
+		}
+
+		want := testParseProgram(tt.want)
+		if expanded.String() != want.String() {
+			t.Errorf("expanded.String not equal to %s: got=%s", want.String(), expanded.String())
+		}
+	}
+}
+
+func TestExpandMacrosErrors(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"amogus m = cheat() { 1 }; m();", "got mogged: cheat returned INTEGER"},
+		{"amogus m = cheat(x) { quote(x) }; m(1, 2);", "mid: want 1 args, got 2"},
+		{"amogus m = cheat() { yap(); }; m();", "cant yap: 0"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		program := testParseProgram(tt.got)
+		eval.DefineMacros(program, env)
+		_, err := eval.ExpandMacros(program, env)
+		testErrorObject(t, err, tt.want)
+	}
+
+	evaled := testEval("amogus f = cook() { cheat() { quote(1) } }; f()")
+	testErrorObject(t, evaled, "delulu: cheat outside top level")
+}
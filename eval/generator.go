@@ -0,0 +1,147 @@
+package eval
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/object"
+)
+
+// panic value used to unwind an abandoned generator body
+type stopGenerator struct{}
+
+// NOTE:
+// the body runs on its own goroutine but only one side
+// is ever active, values are handed over through out and
+// the consumer wakes the body again through in
+type coroutine struct {
+	mu      sync.Mutex
+	body    *ast.BlockStatement
+	env     *object.Environment
+	in      chan bool // true resumes, false stops
+	out     chan object.Object
+	started bool
+	done    bool
+}
+
+func newGenerator(fn *object.Function, env *object.Environment) *object.Generator {
+	co := &coroutine{
+		body: fn.Body,
+		env:  env,
+		in:   make(chan bool),
+		out:  make(chan object.Object),
+	}
+	env.SetYield(co.yield)
+
+	// the goroutine only references co, so an abandoned
+	// generator can still be collected and stop it
+	gen := &object.Generator{Resume: co.resume, Stop: co.stop}
+	runtime.SetFinalizer(gen, func(gen *object.Generator) { gen.Stop() })
+	return gen
+}
+
+func (co *coroutine) run() {
+	defer close(co.out)
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(stopGenerator); !ok {
+				panic(r)
+			}
+		}
+	}()
+
+	// rizz just ends the generator, errors reach the consumer
+	if res := resolveTailCall(Eval(co.body, co.env)); checkError(res) {
+		co.out <- res
+	}
+}
+
+// called by slay on the body goroutine
+func (co *coroutine) yield(val object.Object) {
+	co.out <- val
+	if !<-co.in {
+		panic(stopGenerator{})
+	}
+}
+
+func (co *coroutine) resume() (object.Object, bool) {
+	// a body stepping its own generator would wait on itself
+	if !co.mu.TryLock() {
+		return newError(object.BAD_OPERATOR, "delulu: generator already running"), true
+	}
+	defer co.mu.Unlock()
+
+	if co.done {
+		return nil, false
+	}
+	if co.started {
+		co.in <- true
+	} else {
+		co.started = true
+		go co.run()
+	}
+
+	val, ok := <-co.out
+	if !ok || checkError(val) {
+		co.done = true
+	}
+	return val, ok
+}
+
+func (co *coroutine) stop() {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	if co.done || !co.started {
+		co.done = true
+		return
+	}
+	co.done = true
+
+	// body is parked in yield, unwind it and wait until it exits
+	co.in <- false
+	for range co.out {
+	}
+}
+
+func evalYieldExpression(node *ast.YieldExpression, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if checkError(val) {
+		return val
+	}
+
+	yield, ok := env.Yield()
+	if !ok {
+		return newError(object.BAD_OPERATOR, "slay outside cook")
+	}
+	yield(val)
+	return NULL
+}
+
+// step gen for each element, stopping it when the loop exits early
+func evalGeneratorLoop(node *ast.ForEachExpression, gen *object.Generator, env *object.Environment) object.Object {
+	for {
+		elem, ok := gen.Resume()
+		if !ok {
+			return NULL
+		}
+		if checkError(elem) {
+			return elem
+		}
+
+		if res := declare(env, node.Variable.Value, elem, node); checkError(res) {
+			gen.Stop()
+			return res
+		}
+
+		body := Eval(node.Body, env)
+		if body == nil {
+			continue
+		}
+		if body.Type() == object.RETURN_VAL_OBJECT || body.Type() == object.ERROR_OBJECT {
+			gen.Stop()
+			return body
+		}
+	}
+}
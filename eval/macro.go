@@ -0,0 +1,163 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/object"
+	"github.com/dxtym/skibidi/token"
+)
+
+// quote(x) keeps x as code instead of evaluating it
+func isQuoteCall(node ast.Node) (ast.Expression, bool) {
+	return specialCall(node, "quote")
+}
+
+func isUnquoteCall(node ast.Node) (ast.Expression, bool) {
+	return specialCall(node, "unquote")
+}
+
+func specialCall(node ast.Node, name string) (ast.Expression, bool) {
+	call, ok := node.(*ast.CallExpression)
+	if !ok || len(call.Arguments) != 1 {
+		return nil, false
+	}
+	ident, ok := call.Function.(*ast.Identifier)
+	if !ok || ident.Value != name {
+		return nil, false
+	}
+	return call.Arguments[0], true
+}
+
+// unquote(x) calls inside are evaluated and spliced back in,
+// node is shared with the function body so a copy is modified
+func quote(node ast.Node, env *object.Environment) object.Object {
+	var err object.Object
+	node = ast.Modify(ast.Copy(node), func(node ast.Node) ast.Node {
+		arg, ok := isUnquoteCall(node)
+		if !ok || err != nil {
+			return node
+		}
+
+		val := Eval(arg, env)
+		if checkError(val) {
+			err = val
+			return node
+		}
+
+		exp, ok := objectToNode(val)
+		if !ok {
+			err = newError(object.MACRO_EXPANSION, "got mogged: unquote %s", val.Type())
+			return node
+		}
+		return exp
+	})
+
+	if err != nil {
+		return err
+	}
+	return &object.Quote{Node: node}
+}
+
+func objectToNode(obj object.Object) (ast.Node, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		tok := token.Token{Type: token.INT, Literal: fmt.Sprintf("%d", obj.Value)}
+		return &ast.IntegerLiteral{Token: tok, Value: obj.Value}, true
+	case *object.String:
+		tok := token.Token{Type: token.STRING, Literal: obj.Value}
+		return &ast.StringLiteral{Token: tok, Value: obj.Value}, true
+	case *object.Boolean:
+		tok := token.Token{Type: token.FALSE, Literal: "cap"}
+		if obj.Value {
+			tok = token.Token{Type: token.TRUE, Literal: "fax"}
+		}
+		return &ast.Boolean{Token: tok, Value: obj.Value}, true
+	case *object.Quote:
+		return obj.Node, true
+	default:
+		return nil, false
+	}
+}
+
+// NOTE:
+// top level amogus x = cheat(...) {...}; statements are
+// moved out of the program into env before expansion
+func DefineMacros(program *ast.Program, env *object.Environment) {
+	stmts := []ast.Statement{}
+	for _, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetStatement)
+		if !ok {
+			stmts = append(stmts, stmt)
+			continue
+		}
+		lit, ok := let.Value.(*ast.MacroLiteral)
+		if !ok {
+			stmts = append(stmts, stmt)
+			continue
+		}
+
+		env.Set(let.Name.Value, &object.Macro{
+			Parameters: lit.Parameters,
+			Body:       lit.Body,
+			Env:        env,
+		})
+	}
+
+	program.Statements = stmts
+}
+
+// ExpandMacros replaces macro calls with the code they return,
+// the first failing expansion is returned as an error
+func ExpandMacros(program ast.Node, env *object.Environment) (ast.Node, object.Object) {
+	var err object.Object
+	expanded := ast.Modify(program, func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || err != nil {
+			return node
+		}
+		macro, ok := lookupMacro(call, env)
+		if !ok {
+			return node
+		}
+
+		if len(call.Arguments) != len(macro.Parameters) {
+			err = newError(object.ARITY, "mid: want %d args, got %d", len(macro.Parameters), len(call.Arguments))
+			return node
+		}
+
+		// arguments are handed over as code
+		macroEnv := object.NewEnclosedEnvironment(macro.Env)
+		for i, param := range macro.Parameters {
+			macroEnv.Set(param.Value, &object.Quote{Node: call.Arguments[i]})
+		}
+
+		res := unwrapReturnValue(resolveTailCall(Eval(macro.Body, macroEnv)))
+		if checkError(res) {
+			err = res
+			return node
+		}
+		quoted, ok := res.(*object.Quote)
+		if !ok {
+			err = newError(object.MACRO_EXPANSION, "got mogged: cheat returned %s", res.Type())
+			return node
+		}
+		// the same quote may be spliced in at many call sites
+		return ast.Copy(quoted.Node)
+	})
+
+	return expanded, err
+}
+
+func lookupMacro(call *ast.CallExpression, env *object.Environment) (*object.Macro, bool) {
+	ident, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+	obj, ok := env.Get(ident.Value)
+	if !ok {
+		return nil, false
+	}
+	macro, ok := obj.(*object.Macro)
+	return macro, ok
+}
@@ -0,0 +1,244 @@
+package eval
+
+import (
+	"strings"
+
+	"github.com/dxtym/skibidi/object"
+)
+
+// NOTE: receiver is passed as the first argument
+var methods = map[object.ObjectType]map[string]*object.Builtin{
+	object.STRING_OBJECT: {
+		"len": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				return &object.Integer{Value: len(args[0].(*object.String).Value)}
+			},
+		},
+		"upper": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				return &object.String{Value: strings.ToUpper(args[0].(*object.String).Value)}
+			},
+		},
+		"lower": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				return &object.String{Value: strings.ToLower(args[0].(*object.String).Value)}
+			},
+		},
+		"split": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				sep, ok := args[1].(*object.String)
+				if !ok {
+					return newError(object.TYPE_MISMATCH, "got mogged: %s", args[1].Type())
+				}
+
+				elems := []object.Object{}
+				for _, part := range strings.Split(args[0].(*object.String).Value, sep.Value) {
+					elems = append(elems, &object.String{Value: part})
+				}
+				return &object.Array{Elements: elems}
+			},
+		},
+		"contains": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				sub, ok := args[1].(*object.String)
+				if !ok {
+					return newError(object.TYPE_MISMATCH, "got mogged: %s", args[1].Type())
+				}
+				return boolToBooleanObject(strings.Contains(args[0].(*object.String).Value, sub.Value))
+			},
+		},
+	},
+	object.ARRAY_OBJECT: {
+		"len": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				return &object.Integer{Value: len(args[0].(*object.Array).Elements)}
+			},
+		},
+		// arrays are immutable, push returns a new one
+		"push": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				elems := args[0].(*object.Array).Elements
+				out := make([]object.Object, len(elems), len(elems)+1)
+				copy(out, elems)
+				return &object.Array{Elements: append(out, args[1])}
+			},
+		},
+		"first": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				elems := args[0].(*object.Array).Elements
+				if len(elems) == 0 {
+					return NULL
+				}
+				return elems[0]
+			},
+		},
+		"last": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				elems := args[0].(*object.Array).Elements
+				if len(elems) == 0 {
+					return NULL
+				}
+				return elems[len(elems)-1]
+			},
+		},
+		"contains": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				for _, elem := range args[0].(*object.Array).Elements {
+					if object.Equal(elem, args[1]) {
+						return TRUE
+					}
+				}
+				return FALSE
+			},
+		},
+	},
+	object.MAP_OBJECT: {
+		"len": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				return &object.Integer{Value: args[0].(*object.Map).Len()}
+			},
+		},
+		"keys": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				keys := []object.Object{}
+				for _, pair := range args[0].(*object.Map).Pairs() {
+					keys = append(keys, pair.Key)
+				}
+				return &object.Array{Elements: keys}
+			},
+		},
+		"values": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				vals := []object.Object{}
+				for _, pair := range args[0].(*object.Map).Pairs() {
+					vals = append(vals, pair.Value)
+				}
+				return &object.Array{Elements: vals}
+			},
+		},
+		"has": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				if !object.Hashable(args[1]) {
+					return newError(object.UNHASHABLE, "delulu: %s", args[1].Type())
+				}
+				_, ok := args[0].(*object.Map).Get(args[1])
+				return boolToBooleanObject(ok)
+			},
+		},
+	},
+	object.SET_OBJECT: {
+		"len": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 0); err != nil {
+					return err
+				}
+				return &object.Integer{Value: args[0].(*object.Set).Len()}
+			},
+		},
+		"has": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				return evalInExpression(args[1], args[0])
+			},
+		},
+		// sets are immutable like arrays, add returns a new one
+		"add": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				return newSet(append(args[0].(*object.Set).Elements(), args[1]))
+			},
+		},
+		"union": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				return applySetOperation(unionSets, args[0], args[1])
+			},
+		},
+		"intersect": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				return applySetOperation(intersectSets, args[0], args[1])
+			},
+		},
+		"diff": {
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArity(args, 1); err != nil {
+					return err
+				}
+				return applySetOperation(diffSets, args[0], args[1])
+			},
+		},
+	},
+}
+
+// check argument count without the receiver
+func checkArity(args []object.Object, want int) object.Object {
+	if len(args)-1 != want {
+		return newError(object.ARITY, "mid: want %d args, got %d", want, len(args)-1)
+	}
+	return nil
+}
+
+// bind receiver to a method of its type
+func lookupMethod(recv object.Object, name string) (object.Object, bool) {
+	method, ok := methods[recv.Type()][name]
+	if !ok {
+		return nil, false
+	}
+
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return method.Fn(append([]object.Object{recv}, args...)...)
+		},
+	}, true
+}
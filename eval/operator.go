@@ -0,0 +1,42 @@
+package eval
+
+import (
+	"github.com/dxtym/skibidi/object"
+)
+
+// NOTE:
+// classes overload an operator by naming a method after
+// it, only the left operand is asked and != uses ==
+func evalOperatorMethod(op string, left, right object.Object) (object.Object, bool) {
+	inst, ok := left.(*object.Instance)
+	if !ok {
+		return nil, false
+	}
+
+	name := op
+	if op == "!=" {
+		name = "=="
+	}
+	fn, owner := inst.Class.FindMethod(name)
+	if fn == nil {
+		return nil, false
+	}
+
+	res := applyFunctionArgs(bindMethod(inst, fn, owner), []object.Object{right}, nil)
+	if op == "!=" && !checkError(res) {
+		return boolToBooleanObject(!checkTruthy(res)), true
+	}
+	return res, true
+}
+
+// an instance with an inspect method prints what it returns
+func setInspect(inst *object.Instance) {
+	fn, owner := inst.Class.FindMethod("inspect")
+	if fn == nil {
+		return
+	}
+
+	inst.SetInspect(func() string {
+		return applyFunctionArgs(bindMethod(inst, fn, owner), nil, nil).Inspect()
+	})
+}
@@ -0,0 +1,183 @@
+package eval
+
+import (
+	"strings"
+
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/object"
+)
+
+func evalSetLiteral(node *ast.SetLiteral, env *object.Environment) object.Object {
+	elems := evalExpressions(node.Elements, env)
+	if len(elems) == 1 && checkError(elems[0]) {
+		return elems[0]
+	}
+
+	return newSet(elems)
+}
+
+// build a set keeping the first occurrence of each element
+func newSet(elems []object.Object) object.Object {
+	set := object.NewSet()
+	for _, elem := range elems {
+		if !object.Hashable(elem) {
+			return newError(object.UNHASHABLE, "delulu: %s", elem.Type())
+		}
+		set.Add(elem)
+	}
+	return set
+}
+
+// elements visited by mew (x in ...), maps yield their keys
+// and generators are run to the end
+func iterElements(obj object.Object) ([]object.Object, object.Object) {
+	switch obj := obj.(type) {
+	case *object.Generator:
+		elems := []object.Object{}
+		for {
+			elem, ok := obj.Resume()
+			if !ok {
+				return elems, nil
+			}
+			if checkError(elem) {
+				return nil, elem
+			}
+			elems = append(elems, elem)
+		}
+	case *object.Array:
+		return obj.Elements, nil
+	case *object.Set:
+		return obj.Elements(), nil
+	case *object.Map:
+		keys := []object.Object{}
+		for _, pair := range obj.Pairs() {
+			keys = append(keys, pair.Key)
+		}
+		return keys, nil
+	case *object.String:
+		chars := []object.Object{}
+		for i := 0; i < len(obj.Value); i++ {
+			chars = append(chars, &object.String{Value: string(obj.Value[i])})
+		}
+		return chars, nil
+	default:
+		return nil, newError(object.TYPE_MISMATCH, "got mogged: %s", obj.Type())
+	}
+}
+
+func evalForEachExpression(node *ast.ForEachExpression, env *object.Environment) object.Object {
+	iter := Eval(node.Iterable, env)
+	if checkError(iter) {
+		return iter
+	}
+	if gen, ok := iter.(*object.Generator); ok {
+		return evalGeneratorLoop(node, gen, env)
+	}
+	// channels are received from until closed
+	if ch, ok := iter.(*object.Channel); ok {
+		recv := func() (object.Object, bool) {
+			val, ok := <-ch.Ch
+			return val, ok
+		}
+		return evalGeneratorLoop(node, &object.Generator{Resume: recv, Stop: func() {}}, env)
+	}
+
+	elems, err := iterElements(iter)
+	if err != nil {
+		return err
+	}
+
+	for _, elem := range elems {
+		if res := declare(env, node.Variable.Value, elem, node); checkError(res) {
+			return res
+		}
+
+		body := Eval(node.Body, env)
+		if body == nil {
+			continue
+		}
+		if body.Type() == object.RETURN_VAL_OBJECT || body.Type() == object.ERROR_OBJECT {
+			return body
+		}
+	}
+
+	return NULL
+}
+
+// x in coll: set elements, map keys, array elements or substrings
+func evalInExpression(left, right object.Object) object.Object {
+	switch coll := right.(type) {
+	case *object.Set:
+		if !object.Hashable(left) {
+			return newError(object.UNHASHABLE, "delulu: %s", left.Type())
+		}
+		return boolToBooleanObject(coll.Has(left))
+	case *object.Map:
+		if !object.Hashable(left) {
+			return newError(object.UNHASHABLE, "delulu: %s", left.Type())
+		}
+		_, ok := coll.Get(left)
+		return boolToBooleanObject(ok)
+	case *object.Array:
+		for _, elem := range coll.Elements {
+			if object.Equal(elem, left) {
+				return TRUE
+			}
+		}
+		return FALSE
+	case *object.String:
+		sub, ok := left.(*object.String)
+		if !ok {
+			return newError(object.TYPE_MISMATCH, "touch grass: %s in %s", left.Type(), right.Type())
+		}
+		return boolToBooleanObject(strings.Contains(coll.Value, sub.Value))
+	default:
+		return newError(object.BAD_OPERATOR, "delulu: %s in %s", left.Type(), right.Type())
+	}
+}
+
+type setOperation func(a, b *object.Set) *object.Set
+
+func unionSets(a, b *object.Set) *object.Set {
+	out := object.NewSet()
+	for _, elem := range a.Elements() {
+		out.Add(elem)
+	}
+	for _, elem := range b.Elements() {
+		out.Add(elem)
+	}
+	return out
+}
+
+func intersectSets(a, b *object.Set) *object.Set {
+	out := object.NewSet()
+	for _, elem := range a.Elements() {
+		if b.Has(elem) {
+			out.Add(elem)
+		}
+	}
+	return out
+}
+
+func diffSets(a, b *object.Set) *object.Set {
+	out := object.NewSet()
+	for _, elem := range a.Elements() {
+		if !b.Has(elem) {
+			out.Add(elem)
+		}
+	}
+	return out
+}
+
+// apply op to two sets, anything else gets mogged
+func applySetOperation(op setOperation, left, right object.Object) object.Object {
+	a, ok := left.(*object.Set)
+	if !ok {
+		return newError(object.TYPE_MISMATCH, "got mogged: %s", left.Type())
+	}
+	b, ok := right.(*object.Set)
+	if !ok {
+		return newError(object.TYPE_MISMATCH, "got mogged: %s", right.Type())
+	}
+	return op(a, b)
+}
@@ -0,0 +1,69 @@
+package eval
+
+import (
+	"reflect"
+
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/object"
+)
+
+// callee and arguments are evaluated before the task starts
+func evalSpawnExpression(node *ast.SpawnExpression, env *object.Environment) object.Object {
+	call, err := evalCall(node.Call, env)
+	if err != nil {
+		return err
+	}
+
+	task := object.NewTask()
+	go func() {
+		task.Finish(applyFunctionArgs(call.Fn, call.Args, call.Named))
+	}()
+	return task
+}
+
+// go panics on a closed channel, turn that into an error
+func sendChannel(ch *object.Channel, val object.Object) (res object.Object) {
+	defer func() {
+		if recover() != nil {
+			res = newError(object.BAD_OPERATOR, "delulu: send on closed chan")
+		}
+	}()
+
+	ch.Ch <- val
+	return NULL
+}
+
+func closeChannel(ch *object.Channel) (res object.Object) {
+	defer func() {
+		if recover() != nil {
+			res = newError(object.BAD_OPERATOR, "delulu: chan already closed")
+		}
+	}()
+
+	close(ch.Ch)
+	return NULL
+}
+
+// NOTE:
+// waits for the first channel with a value and
+// returns [index, value], value is NULL once closed
+func selectChannels(chans []object.Object) object.Object {
+	cases := make([]reflect.SelectCase, 0, len(chans))
+	for _, obj := range chans {
+		ch, ok := obj.(*object.Channel)
+		if !ok {
+			return newError(object.TYPE_MISMATCH, "got mogged: %s", obj.Type())
+		}
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch.Ch)})
+	}
+	if len(cases) == 0 {
+		return newError(object.ARITY, "mid: select needs a chan")
+	}
+
+	i, val, ok := reflect.Select(cases)
+	elems := []object.Object{&object.Integer{Value: i}, NULL}
+	if ok {
+		elems[1] = val.Interface().(object.Object)
+	}
+	return &object.Array{Elements: elems}
+}
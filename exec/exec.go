@@ -8,6 +8,8 @@ import (
 	"os/user"
 	"path/filepath"
 
+	"github.com/dxtym/skibidi/ast"
+	"github.com/dxtym/skibidi/check"
 	"github.com/dxtym/skibidi/eval"
 	"github.com/dxtym/skibidi/lexer"
 	"github.com/dxtym/skibidi/object"
@@ -21,15 +23,66 @@ const (
 
 func Run(in io.Reader, out io.Writer, args []string) {
 	env := object.NewEnvironment()
+	macros := object.NewEnvironment()
 
-	if len(args) > 1 {
-		runFile(out, env, args[1])
-	} else {
-		runRepl(in, out, env)
+	switch {
+	case len(args) > 1 && args[1] == "explain":
+		runExplain(out, args[2:])
+	case len(args) > 2 && args[1] == "check":
+		runCheck(out, macros, args[2])
+	case len(args) > 1:
+		runFile(out, env, macros, args[1])
+	default:
+		runRepl(in, out, env, macros)
 	}
 }
 
-func runFile(out io.Writer, env *object.Environment, file string) {
+// describe error codes, all of them without arguments
+func runExplain(out io.Writer, codes []string) {
+	if len(codes) == 0 {
+		for _, kind := range object.ErrorKinds {
+			fmt.Fprintf(out, "%s %s: %s\n", kind.Code, kind.Name, kind.Description)
+		}
+		return
+	}
+
+	for _, code := range codes {
+		kind, ok := object.LookupErrorKind(code)
+		if !ok {
+			io.WriteString(out, "skill issue: unknown code "+code+"\n")
+			os.Exit(1)
+		}
+		fmt.Fprintf(out, "%s %s: %s\n", kind.Code, kind.Name, kind.Description)
+	}
+}
+
+func runFile(out io.Writer, env, macros *object.Environment, file string) {
+	parseProgram(out, env, macros, readFile(out, file))
+}
+
+// report type mistakes without running the file,
+// inferred ones are listed but do not fail the check
+func runCheck(out io.Writer, macros *object.Environment, file string) {
+	program := expandProgram(out, macros, readFile(out, file))
+	if program == nil {
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, e := range check.Check(program) {
+		if e.Inferred {
+			io.WriteString(out, file+":"+e.Error()+" (inferred)\n")
+			continue
+		}
+		io.WriteString(out, file+":"+e.Error()+"\n")
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func readFile(out io.Writer, file string) string {
 	if filepath.Ext(file) != EXT {
 		io.WriteString(out, "red flag")
 		os.Exit(1)
@@ -41,10 +94,10 @@ func runFile(out io.Writer, env *object.Environment, file string) {
 		os.Exit(1)
 	}
 
-	parseProgram(out, env, string(text))
+	return string(text)
 }
 
-func runRepl(in io.Reader, out io.Writer, env *object.Environment) {
+func runRepl(in io.Reader, out io.Writer, env, macros *object.Environment) {
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -60,11 +113,33 @@ func runRepl(in io.Reader, out io.Writer, env *object.Environment) {
 		if !scanned {
 			return
 		}
-		parseProgram(out, env, scanner.Text())
+		parseProgram(out, env, macros, scanner.Text())
 	}
 }
 
-func parseProgram(out io.Writer, env *object.Environment, text string) {
+// macros live in their own env and expand before eval
+func parseProgram(out io.Writer, env, macros *object.Environment, text string) {
+	program := expandProgram(out, macros, text)
+	if program == nil {
+		return
+	}
+	checkProgram(out, program)
+
+	evaled := eval.Eval(program, env)
+	if evaled != nil {
+		io.WriteString(out, evaled.Inspect())
+		io.WriteString(out, "\n")
+	}
+
+	// timers and awaited work left over run after the program
+	if err := eval.RunLoop(); err != nil {
+		io.WriteString(out, err.Inspect())
+		io.WriteString(out, "\n")
+	}
+}
+
+// parse text and expand its macros, nil if expansion fails
+func expandProgram(out io.Writer, macros *object.Environment, text string) *ast.Program {
 	l := lexer.NewLexer(text)
 	p := parser.NewParser(l)
 
@@ -76,9 +151,28 @@ func parseProgram(out io.Writer, env *object.Environment, text string) {
 		os.Exit(1)
 	}
 
-	evaled := eval.Eval(program, env)
-	if evaled != nil {
-		io.WriteString(out, evaled.Inspect())
+	eval.DefineMacros(program, macros)
+	expanded, err := eval.ExpandMacros(program, macros)
+	if err != nil {
+		io.WriteString(out, err.Inspect())
 		io.WriteString(out, "\n")
+		return nil
+	}
+
+	return expanded.(*ast.Program)
+}
+
+// mistakes against annotations stop the program before it
+// runs, inferred ones are left for eval to report if they happen
+func checkProgram(out io.Writer, program *ast.Program) {
+	failed := false
+	for _, e := range check.Check(program) {
+		if !e.Inferred {
+			io.WriteString(out, e.Error()+"\n")
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
 	}
 }
@@ -3,20 +3,28 @@ package lexer
 import "github.com/dxtym/skibidi/token"
 
 type Lexer struct {
-	input string
-	pos   int // current pos pointing to char
-	nxt   int // next pos after current pos
-	char  byte
+	input  string
+	pos    int // current pos pointing to char
+	nxt    int // next pos after current pos
+	char   byte
+	line   int // position of char for tokens
+	column int
 }
 
 func NewLexer(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
 // TODO: cover unicode
 func (l *Lexer) readChar() {
+	if l.char == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+
 	if l.nxt >= len(l.input) {
 		l.char = 0
 	} else {
@@ -28,10 +36,17 @@ func (l *Lexer) readChar() {
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhitespace()
 
+	line, column := l.line, l.column
+	tok := l.readToken()
+	tok.Line, tok.Column = line, column
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	switch l.char {
 	case '=':
 		tok = l.makeTwoCharToken() // TODO: should it be a lexer method?
@@ -55,8 +70,12 @@ func (l *Lexer) NextToken() token.Token {
 		tok = token.NewToken(token.SEMICOLON, l.char)
 	case ',':
 		tok = token.NewToken(token.COMMA, l.char)
+	case '.':
+		tok = l.makeDotToken()
 	case '!':
 		tok = l.makeTwoCharToken()
+	case '?':
+		tok = l.makeQuestionToken()
 	case '-':
 		tok = token.NewToken(token.MINUS, l.char)
 	case '/':
@@ -64,9 +83,17 @@ func (l *Lexer) NextToken() token.Token {
 	case '*':
 		tok = token.NewToken(token.MUL, l.char)
 	case '<':
-		tok = token.NewToken(token.LESS, l.char)
+		tok = l.makeShiftToken(token.LESS, token.LSHIFT)
 	case '>':
-		tok = token.NewToken(token.MORE, l.char)
+		tok = l.makeShiftToken(token.MORE, token.RSHIFT)
+	case '&':
+		tok = token.NewToken(token.BITAND, l.char)
+	case '|':
+		tok = l.makePipeToken()
+	case '^':
+		tok = token.NewToken(token.BITXOR, l.char)
+	case '~':
+		tok = token.NewToken(token.BITNOT, l.char)
 	case '"':
 		tok.Type = token.STRING
 		tok.Literal = l.readString() // TODO: add support for escape chars
@@ -79,7 +106,7 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Type = token.LookUpIdent(tok.Literal) // check if keyword
 			return tok
 		} else if isInteger(l.char) {
-			tok.Literal = l.readIdent()
+			tok.Literal = l.readNumber()
 			tok.Type = token.INT
 			return tok
 		} else {
@@ -99,6 +126,16 @@ func (l *Lexer) readIdent() string {
 	return l.input[start:l.pos] // l.pos no longer letter or integer
 }
 
+// prefixes and separators are checked by the parser,
+// so 0x1F, 0b1010, 0o17 and 1_000 are read whole
+func (l *Lexer) readNumber() string {
+	start := l.pos
+	for isLetter(l.char) || isInteger(l.char) || l.char == '_' {
+		l.readChar()
+	}
+	return l.input[start:l.pos]
+}
+
 func isLetter(char byte) bool {
 	return 'a' <= char && char <= 'z' || 'A' <= char && char <= 'Z'
 }
@@ -125,6 +162,10 @@ func (l *Lexer) peekChar() byte {
 // compose tokens with two chars
 func (l *Lexer) makeTwoCharToken() token.Token {
 	ch := l.char
+	if ch == '=' && l.peekChar() == '>' {
+		l.readChar()
+		return token.Token{Type: token.ARROW, Literal: "=>"}
+	}
 	if l.peekChar() == '=' {
 		l.readChar()
 		if ch == '=' {
@@ -143,6 +184,52 @@ func (l *Lexer) makeTwoCharToken() token.Token {
 	return token.Token{Type: token.ILLEGAL, Literal: string(ch) + string(l.char)}
 }
 
+// compose tokens starting with a dot
+func (l *Lexer) makeDotToken() token.Token {
+	if l.peekChar() == '.' && l.nxt+1 < len(l.input) && l.input[l.nxt+1] == '.' {
+		l.readChar()
+		l.readChar()
+		return token.Token{Type: token.ELLIPSIS, Literal: "..."}
+	}
+
+	return token.NewToken(token.DOT, l.char)
+}
+
+// a doubled < or > is a shift
+func (l *Lexer) makeShiftToken(single, double token.TokenType) token.Token {
+	if l.peekChar() == l.char {
+		ch := l.char
+		l.readChar()
+		return token.Token{Type: double, Literal: string(ch) + string(ch)}
+	}
+
+	return token.NewToken(single, l.char)
+}
+
+// |> pipes, a lone | is bitwise or
+func (l *Lexer) makePipeToken() token.Token {
+	if l.peekChar() == '>' {
+		l.readChar()
+		return token.Token{Type: token.PIPE, Literal: "|>"}
+	}
+
+	return token.NewToken(token.BITOR, l.char)
+}
+
+// ? only appears in ?. and ??
+func (l *Lexer) makeQuestionToken() token.Token {
+	switch l.peekChar() {
+	case '.':
+		l.readChar()
+		return token.Token{Type: token.OPTIONAL, Literal: "?."}
+	case '?':
+		l.readChar()
+		return token.Token{Type: token.COALESCE, Literal: "??"}
+	default:
+		return token.NewToken(token.ILLEGAL, l.char)
+	}
+}
+
 func (l *Lexer) readString() string {
 	pos := l.pos + 1
 	for {
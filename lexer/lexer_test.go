@@ -58,3 +58,146 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestDotTokens(t *testing.T) {
+	input := `cook(...xs) { f(...xs) } p.x`
+
+	tests := []struct {
+		got  token.TokenType
+		want string
+	}{
+		{token.FUNC, "cook"},
+		{token.LPAREN, "("},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "xs"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "xs"},
+		{token.RPAREN, ")"},
+		{token.RBRACE, "}"},
+		{token.IDENT, "p"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	for _, tt := range tests {
+		token := l.NextToken()
+		if token.Type != tt.got {
+			t.Errorf("token.Type not equal to %s: got=%s", tt.got, token.Type)
+		}
+		if token.Literal != tt.want {
+			t.Errorf("token.Literal not equal to %s: got=%s", tt.want, token.Literal)
+		}
+	}
+}
+
+func TestQuestionTokens(t *testing.T) {
+	input := `a?.b?.[0] ?? c ?`
+
+	tests := []struct {
+		got  token.TokenType
+		want string
+	}{
+		{token.IDENT, "a"},
+		{token.OPTIONAL, "?."},
+		{token.IDENT, "b"},
+		{token.OPTIONAL, "?."},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.COALESCE, "??"},
+		{token.IDENT, "c"},
+		{token.ILLEGAL, "?"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	for _, tt := range tests {
+		token := l.NextToken()
+		if token.Type != tt.got {
+			t.Errorf("token.Type not equal to %s: got=%s", tt.got, token.Type)
+		}
+		if token.Literal != tt.want {
+			t.Errorf("token.Literal not equal to %s: got=%s", tt.want, token.Literal)
+		}
+	}
+}
+
+func TestNumberAndBitwiseTokens(t *testing.T) {
+	input := `0x1F 0b1010 0o17 1_000 ~a & b | c ^ d << 2 >> 1 < > |>`
+
+	tests := []struct {
+		got  token.TokenType
+		want string
+	}{
+		{token.INT, "0x1F"},
+		{token.INT, "0b1010"},
+		{token.INT, "0o17"},
+		{token.INT, "1_000"},
+		{token.BITNOT, "~"},
+		{token.IDENT, "a"},
+		{token.BITAND, "&"},
+		{token.IDENT, "b"},
+		{token.BITOR, "|"},
+		{token.IDENT, "c"},
+		{token.BITXOR, "^"},
+		{token.IDENT, "d"},
+		{token.LSHIFT, "<<"},
+		{token.INT, "2"},
+		{token.RSHIFT, ">>"},
+		{token.INT, "1"},
+		{token.LESS, "<"},
+		{token.MORE, ">"},
+		{token.PIPE, "|>"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+	for _, tt := range tests {
+		token := l.NextToken()
+		if token.Type != tt.got {
+			t.Errorf("token.Type not equal to %s: got=%s", tt.got, token.Type)
+		}
+		if token.Literal != tt.want {
+			t.Errorf("token.Literal not equal to %s: got=%s", tt.want, token.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "amogus x = 1;\n  yap(\"a\nb\", x)"
+
+	tests := []struct {
+		want   string
+		line   int
+		column int
+	}{
+		{"amogus", 1, 1},
+		{"x", 1, 8},
+		{"=", 1, 10},
+		{"1", 1, 12},
+		{";", 1, 13},
+		{"yap", 2, 3},
+		{"(", 2, 6},
+		{"a\nb", 2, 7},
+		{",", 3, 3},
+		{"x", 3, 5},
+		{")", 3, 6},
+	}
+
+	l := NewLexer(input)
+	for _, tt := range tests {
+		token := l.NextToken()
+		if token.Literal != tt.want {
+			t.Errorf("token.Literal not equal to %s: got=%s", tt.want, token.Literal)
+		}
+		if token.Line != tt.line || token.Column != tt.column {
+			t.Errorf("%s not at %d:%d: got=%d:%d", tt.want, tt.line, tt.column, token.Line, token.Column)
+		}
+	}
+}
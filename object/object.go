@@ -2,8 +2,13 @@ package object
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/dxtym/skibidi/ast"
 	"github.com/spaolacci/murmur3"
@@ -13,16 +18,28 @@ type ObjectType string
 type BuiltinFunction func(args ...Object) Object
 
 const (
-	INTEGER_OBJECT    = "INTEGER"
-	STRING_OBJECT     = "STRING"
-	BOOLEAN_OBJECT    = "BOOLEAN"
-	NULL_OBJECT       = "NULL"
-	RETURN_VAL_OBJECT = "RETURN_VAL"
-	ERROR_OBJECT      = "ERROR"
-	FUNCTION_OBJECT   = "FUNCTION"
-	BUILTIN_OBJECT    = "BUILTIN"
-	ARRAY_OBJECT      = "ARRAY"
-	MAP_OBJECT        = "MAP"
+	INTEGER_OBJECT     = "INTEGER"
+	STRING_OBJECT      = "STRING"
+	BOOLEAN_OBJECT     = "BOOLEAN"
+	NULL_OBJECT        = "NULL"
+	RETURN_VAL_OBJECT  = "RETURN_VAL"
+	ERROR_OBJECT       = "ERROR"
+	FUNCTION_OBJECT    = "FUNCTION"
+	BUILTIN_OBJECT     = "BUILTIN"
+	ARRAY_OBJECT       = "ARRAY"
+	MAP_OBJECT         = "MAP"
+	SET_OBJECT         = "SET"
+	RECORD_TYPE_OBJECT = "RECORD_TYPE"
+	RECORD_OBJECT      = "RECORD"
+	CLASS_OBJECT       = "CLASS"
+	INSTANCE_OBJECT    = "INSTANCE"
+	SUPER_OBJECT       = "SUPER"
+	GENERATOR_OBJECT   = "GENERATOR"
+	QUOTE_OBJECT       = "QUOTE"
+	MACRO_OBJECT       = "MACRO"
+	TASK_OBJECT        = "TASK"
+	CHANNEL_OBJECT     = "CHANNEL"
+	PROMISE_OBJECT     = "PROMISE"
 )
 
 type Object interface {
@@ -39,6 +56,7 @@ func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 
 type String struct {
 	Value string
+	hash  atomic.Pointer[Hash] // cached result of Hash, tasks share strings
 }
 
 func (s *String) Type() ObjectType { return STRING_OBJECT }
@@ -58,28 +76,151 @@ func (n *Null) Inspect() string  { return "null" }
 
 type ReturnValue struct {
 	Value Object
+	Tail  *TailCall // set instead of Value by rizz f(...)
 }
 
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VAL_OBJECT }
-func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+func (rv *ReturnValue) Inspect() string {
+	if rv.Tail != nil {
+		return "tail call"
+	}
+	return rv.Value.Inspect()
+}
+
+// call in tail position left for the caller to run
+type TailCall struct {
+	Fn    Object
+	Args  []Object
+	Named map[string]Object
+}
+
+// stable machine-readable classification of an error
+type ErrorKind struct {
+	Code        string
+	Name        string
+	Description string
+}
+
+var (
+	TYPE_MISMATCH = ErrorKind{
+		Code:        "E001",
+		Name:        "type_mismatch",
+		Description: "an operation got a value of the wrong type, like 1 + fax or -\"a\"",
+	}
+	UNKNOWN_IDENTIFIER = ErrorKind{
+		Code:        "E002",
+		Name:        "unknown_identifier",
+		Description: "a name was used before being bound with amogus",
+	}
+	BAD_OPERATOR = ErrorKind{
+		Code:        "E003",
+		Name:        "bad_operator",
+		Description: "the operator is not defined for these operands, like fax + cap, or ... is used outside a call or array",
+	}
+	INDEX_OUT_OF_RANGE = ErrorKind{
+		Code:        "E004",
+		Name:        "index_out_of_range",
+		Description: "an index is past either end of an array or string, or a slice step is 0",
+	}
+	NOT_CALLABLE = ErrorKind{
+		Code:        "E005",
+		Name:        "not_callable",
+		Description: "a value that is not a function, record or class was called",
+	}
+	ARITY = ErrorKind{
+		Code:        "E006",
+		Name:        "arity",
+		Description: "a call passed too many, too few, unknown or duplicate arguments",
+	}
+	UNKNOWN_MEMBER = ErrorKind{
+		Code:        "E007",
+		Name:        "unknown_member",
+		Description: "a field or method accessed with a dot does not exist on the value",
+	}
+	UNHASHABLE = ErrorKind{
+		Code:        "E008",
+		Name:        "unhashable",
+		Description: "only integers, strings, booleans and arrays of them can be map keys",
+	}
+	THROWN = ErrorKind{
+		Code:        "E009",
+		Name:        "thrown",
+		Description: "a value raised with yeet was not caught by sus/cope",
+	}
+	CONST_REASSIGN = ErrorKind{
+		Code:        "E010",
+		Name:        "const_reassign",
+		Description: "a name bound with nocap was declared again in the same scope",
+	}
+	MACRO_EXPANSION = ErrorKind{
+		Code:        "E011",
+		Name:        "macro_expansion",
+		Description: "a cheat macro did not return a quote, or was defined outside the top level",
+	}
+	BAD_ARGUMENT = ErrorKind{
+		Code:        "E012",
+		Name:        "bad_argument",
+		Description: "a number is outside what the operation accepts, like a negative shift count, channel size or delay",
+	}
+)
+
+var ErrorKinds = []ErrorKind{
+	TYPE_MISMATCH,
+	UNKNOWN_IDENTIFIER,
+	BAD_OPERATOR,
+	INDEX_OUT_OF_RANGE,
+	NOT_CALLABLE,
+	ARITY,
+	UNKNOWN_MEMBER,
+	UNHASHABLE,
+	THROWN,
+	CONST_REASSIGN,
+	MACRO_EXPANSION,
+	BAD_ARGUMENT,
+}
+
+// LookupErrorKind finds a kind by its code or name
+func LookupErrorKind(key string) (ErrorKind, bool) {
+	for _, kind := range ErrorKinds {
+		if strings.EqualFold(kind.Code, key) || kind.Name == key {
+			return kind, true
+		}
+	}
+	return ErrorKind{}, false
+}
 
 // TODO: add stack trace from extra fields of token
 type Error struct {
-	Message string
+	Message string    // slang presentation
+	Kind    ErrorKind // stable classification
+	Value   Object    // thrown value, nil for runtime errors
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJECT }
-func (e *Error) Inspect() string  { return fmt.Sprintf("%s", e.Message) }
+func (e *Error) Inspect() string {
+	if e.Kind.Code == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s [%s]", e.Message, e.Kind.Code)
+}
 
+// NOTE:
+// tasks share closures, so every scope is
+// guarded by its own lock
 type Environment struct {
-	store map[string]Object
-	other *Environment
+	mu     sync.RWMutex
+	store  map[string]Object
+	consts map[string]ast.Node   // const names in this scope and their declarations
+	yield  func(Object)          // set on the call scope of a generator
+	await  func(*Promise) Object // set on the call scope of an async function
+	other  *Environment
 }
 
 func NewEnvironment() *Environment {
 	return &Environment{
-		store: make(map[string]Object),
-		other: nil,
+		store:  make(map[string]Object),
+		consts: make(map[string]ast.Node),
+		other:  nil,
 	}
 }
 
@@ -90,7 +231,9 @@ func NewEnclosedEnvironment(other *Environment) *Environment {
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
+	e.mu.RLock()
 	val, ok := e.store[name]
+	e.mu.RUnlock()
 	if !ok && e.other != nil {
 		val, ok = e.other.Get(name)
 	}
@@ -98,14 +241,67 @@ func (e *Environment) Get(name string) (Object, bool) {
 }
 
 func (e *Environment) Set(name string, val Object) Object {
+	e.mu.Lock()
 	e.store[name] = val
+	e.mu.Unlock()
 	return val
 }
 
+// SetConst binds name as a constant declared by site
+func (e *Environment) SetConst(name string, val Object, site ast.Node) Object {
+	e.mu.Lock()
+	e.consts[name] = site
+	e.mu.Unlock()
+	return e.Set(name, val)
+}
+
+// ConstSite only looks at this scope, outer consts can be shadowed
+func (e *Environment) ConstSite(name string) (ast.Node, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	site, ok := e.consts[name]
+	return site, ok
+}
+
+// SetYield makes slay in this scope hand values to fn
+func (e *Environment) SetYield(fn func(Object)) {
+	e.yield = fn
+}
+
+// Yield finds the nearest generator scope
+func (e *Environment) Yield() (func(Object), bool) {
+	for env := e; env != nil; env = env.other {
+		if env.yield != nil {
+			return env.yield, true
+		}
+	}
+	return nil, false
+}
+
+// SetAwait makes holdup in this scope suspend through fn
+func (e *Environment) SetAwait(fn func(*Promise) Object) {
+	e.await = fn
+}
+
+// Await finds the nearest async function scope
+func (e *Environment) Await() (func(*Promise) Object, bool) {
+	for env := e; env != nil; env = env.other {
+		if env.await != nil {
+			return env.await, true
+		}
+	}
+	return nil, false
+}
+
 type Function struct {
 	Parameters []*ast.Identifier
+	Defaults   map[string]ast.Expression
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Generator  bool // calls return a Generator instead of running Body
+	Async      bool // calls return a Promise
+	Arrow      bool // Body is a single rizz from cook(x) => x
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJECT }
@@ -114,17 +310,185 @@ func (f *Function) Inspect() string {
 
 	args := []string{}
 	for _, arg := range f.Parameters {
-		args = append(args, arg.String())
+		if def, ok := f.Defaults[arg.Value]; ok {
+			args = append(args, arg.String()+" = "+def.String())
+		} else {
+			args = append(args, arg.String())
+		}
+	}
+	if f.Rest != nil {
+		args = append(args, "..."+f.Rest.String())
 	}
 
+	if f.Async {
+		out.WriteString("lowkey ")
+	}
 	out.WriteString("cook(")
 	out.WriteString(strings.Join(args, ", "))
+	if f.Arrow {
+		ret := f.Body.Statements[0].(*ast.ReturnStatement)
+		out.WriteString(") => ")
+		out.WriteString(ret.Value.String())
+		return out.String()
+	}
 	out.WriteString(") {\n")
 	out.WriteString(f.Body.String())
 	out.WriteString("\n}")
 	return out.String()
 }
 
+// NOTE:
+// generator bodies run lazily, Resume steps to the
+// next slay and Stop abandons whatever is left
+type Generator struct {
+	Resume func() (Object, bool) // false once the body is done
+	Stop   func()
+}
+
+func (g *Generator) Type() ObjectType { return GENERATOR_OBJECT }
+func (g *Generator) Inspect() string  { return "generator" }
+
+// unevaluated code produced by quote
+type Quote struct {
+	Node ast.Node
+}
+
+func (q *Quote) Type() ObjectType { return QUOTE_OBJECT }
+func (q *Quote) Inspect() string  { return "QUOTE(" + q.Node.String() + ")" }
+
+type Macro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (m *Macro) Type() ObjectType { return MACRO_OBJECT }
+func (m *Macro) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range m.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("cheat(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(m.Body.String())
+	out.WriteString("\n}")
+	return out.String()
+}
+
+// function call running on its own goroutine
+type Task struct {
+	done   chan struct{}
+	result Object
+}
+
+func NewTask() *Task {
+	return &Task{done: make(chan struct{})}
+}
+
+// Finish stores the result and wakes everyone waiting
+func (t *Task) Finish(res Object) {
+	t.result = res
+	close(t.done)
+}
+
+// Wait blocks until the task is finished
+func (t *Task) Wait() Object {
+	<-t.done
+	return t.result
+}
+
+func (t *Task) Type() ObjectType { return TASK_OBJECT }
+func (t *Task) Inspect() string  { return "task" }
+
+// NOTE:
+// a promise settles once with a value, or with an
+// *Error when rejected. waiters run on the settling side
+type Promise struct {
+	mu      sync.Mutex
+	settled bool
+	handled bool // outcome was read or waited on
+	value   Object
+	waiters []func()
+}
+
+func NewPromise() *Promise {
+	return &Promise{}
+}
+
+// Settle fixes the outcome, later calls are ignored
+func (p *Promise) Settle(val Object) bool {
+	p.mu.Lock()
+	if p.settled {
+		p.mu.Unlock()
+		return false
+	}
+	p.settled = true
+	p.value = val
+	waiters := p.waiters
+	p.waiters = nil
+	p.mu.Unlock()
+
+	for _, fn := range waiters {
+		fn()
+	}
+	return true
+}
+
+// Result gives the outcome once settled and marks it handled
+func (p *Promise) Result() (Object, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.settled {
+		return nil, false
+	}
+	p.handled = true
+	return p.value, true
+}
+
+// Then runs fn once settled, right away if it already is
+func (p *Promise) Then(fn func()) {
+	p.mu.Lock()
+	p.handled = true
+	if !p.settled {
+		p.waiters = append(p.waiters, fn)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	fn()
+}
+
+func (p *Promise) Handled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.handled
+}
+
+func (p *Promise) Type() ObjectType { return PROMISE_OBJECT }
+func (p *Promise) Inspect() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case !p.settled:
+		return "promise(pending)"
+	case p.value.Type() == ERROR_OBJECT:
+		return "promise(rejected: " + p.value.Inspect() + ")"
+	default:
+		return "promise(" + p.value.Inspect() + ")"
+	}
+}
+
+type Channel struct {
+	Ch chan Object
+}
+
+func (c *Channel) Type() ObjectType { return CHANNEL_OBJECT }
+func (c *Channel) Inspect() string  { return "chan" }
+
 type Builtin struct {
 	Fn BuiltinFunction
 }
@@ -134,6 +498,7 @@ func (b *Builtin) Inspect() string  { return "builtin function" }
 
 type Array struct {
 	Elements []Object
+	hash     atomic.Pointer[Hash] // cached result of Hash, tasks share arrays
 }
 
 func (a *Array) Type() ObjectType { return ARRAY_OBJECT }
@@ -155,21 +520,48 @@ type Hasher interface {
 	Hash() Hash
 }
 
-// TODO: cache return values
+// NOTE:
+// different values may share a hash, so
+// lookups compare keys with Equal as well
 type Hash struct {
 	Type  ObjectType
 	Value uint64
 }
 
+// Hashable reports whether obj can be used as a map key,
+// arrays qualify when all of their elements do
+func Hashable(obj Object) bool {
+	switch obj := obj.(type) {
+	case *Array:
+		for _, elem := range obj.Elements {
+			if !Hashable(elem) {
+				return false
+			}
+		}
+		return true
+	case Hasher:
+		return true
+	default:
+		return false
+	}
+}
+
 func (i *Integer) Hash() Hash {
 	return Hash{Type: i.Type(), Value: uint64(i.Value)}
 }
 
 func (s *String) Hash() Hash {
+	if hash := s.hash.Load(); hash != nil {
+		return *hash
+	}
+
 	h := murmur3.New64()
 	val := []byte(s.Value)
 	h.Write(val)
-	return Hash{Type: s.Type(), Value: h.Sum64()}
+
+	hash := &Hash{Type: s.Type(), Value: h.Sum64()}
+	s.hash.Store(hash)
+	return *hash
 }
 
 func (b *Boolean) Hash() Hash {
@@ -184,13 +576,84 @@ func (b *Boolean) Hash() Hash {
 	return Hash{Type: b.Type(), Value: val}
 }
 
+// arrays are immutable so the hash is computed once
+func (a *Array) Hash() Hash {
+	if hash := a.hash.Load(); hash != nil {
+		return *hash
+	}
+
+	h := murmur3.New64()
+	buf := make([]byte, 8)
+	for _, elem := range a.Elements {
+		hasher, ok := elem.(Hasher)
+		if !ok {
+			continue
+		}
+
+		eh := hasher.Hash()
+		h.Write([]byte(eh.Type))
+		binary.LittleEndian.PutUint64(buf, eh.Value)
+		h.Write(buf)
+	}
+
+	hash := &Hash{Type: a.Type(), Value: h.Sum64()}
+	a.hash.Store(hash)
+	return *hash
+}
+
 type Pair struct {
 	Key   Object
 	Value Object
 }
 
+// NOTE:
+// pairs keep insertion order for iteration while
+// index maps hashes to positions for O(1) lookup.
+// keys must satisfy Hashable
 type Map struct {
-	Pairs map[Hash]Pair
+	pairs []Pair
+	index map[Hash][]int
+}
+
+func NewMap() *Map {
+	return &Map{index: make(map[Hash][]int)}
+}
+
+func (m *Map) Get(key Object) (Object, bool) {
+	if i, ok := m.find(key); ok {
+		return m.pairs[i].Value, true
+	}
+	return nil, false
+}
+
+func (m *Map) Set(key, val Object) {
+	if i, ok := m.find(key); ok {
+		m.pairs[i].Value = val
+		return
+	}
+
+	hash := key.(Hasher).Hash()
+	m.index[hash] = append(m.index[hash], len(m.pairs))
+	m.pairs = append(m.pairs, Pair{Key: key, Value: val})
+}
+
+// position of key in pairs
+func (m *Map) find(key Object) (int, bool) {
+	for _, i := range m.index[key.(Hasher).Hash()] {
+		if Equal(m.pairs[i].Key, key) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (m *Map) Len() int { return len(m.pairs) }
+
+// Pairs returns pairs in insertion order
+func (m *Map) Pairs() []Pair {
+	pairs := make([]Pair, len(m.pairs))
+	copy(pairs, m.pairs)
+	return pairs
 }
 
 func (m *Map) Type() ObjectType { return MAP_OBJECT }
@@ -198,7 +661,7 @@ func (m *Map) Inspect() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for _, val := range m.Pairs {
+	for _, val := range m.Pairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s", val.Key.Inspect(), val.Value.Inspect()))
 	}
 
@@ -208,3 +671,271 @@ func (m *Map) Inspect() string {
 
 	return out.String()
 }
+
+// NOTE:
+// set reuses the map's ordered hash index with
+// elements as keys. elements must satisfy Hashable
+type Set struct {
+	items *Map
+}
+
+func NewSet() *Set {
+	return &Set{items: NewMap()}
+}
+
+func (s *Set) Add(elem Object) { s.items.Set(elem, elem) }
+
+func (s *Set) Has(elem Object) bool {
+	_, ok := s.items.find(elem)
+	return ok
+}
+
+func (s *Set) Len() int { return s.items.Len() }
+
+// Elements returns elements in insertion order
+func (s *Set) Elements() []Object {
+	elems := make([]Object, 0, s.items.Len())
+	for _, pair := range s.items.pairs {
+		elems = append(elems, pair.Key)
+	}
+	return elems
+}
+
+func (s *Set) Type() ObjectType { return SET_OBJECT }
+func (s *Set) Inspect() string {
+	if s.Len() == 0 {
+		return "set()" // {} is an empty map
+	}
+
+	var out bytes.Buffer
+
+	elems := []string{}
+	for _, elem := range s.Elements() {
+		elems = append(elems, elem.Inspect())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(elems, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// named type with a fixed set of fields
+type RecordType struct {
+	Name   string
+	Fields []string
+}
+
+func (rt *RecordType) Type() ObjectType { return RECORD_TYPE_OBJECT }
+func (rt *RecordType) Inspect() string {
+	return fmt.Sprintf("squad %s {%s}", rt.Name, strings.Join(rt.Fields, ", "))
+}
+
+func (rt *RecordType) HasField(name string) bool {
+	for _, f := range rt.Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NOTE:
+// tasks share records, so once built their
+// fields are only touched through Get and Set
+type Record struct {
+	mu     sync.RWMutex
+	Def    *RecordType
+	Fields map[string]Object
+}
+
+func (r *Record) Get(name string) (Object, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	val, ok := r.Fields[name]
+	return val, ok
+}
+
+func (r *Record) Set(name string, val Object) {
+	r.mu.Lock()
+	r.Fields[name] = val
+	r.mu.Unlock()
+}
+
+// copy of the fields, values are inspected without the lock
+func (r *Record) snapshot() map[string]Object {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.Fields)
+}
+
+func (r *Record) Type() ObjectType { return RECORD_OBJECT }
+func (r *Record) Inspect() string {
+	var out bytes.Buffer
+
+	snap := r.snapshot()
+	fields := []string{}
+	for _, name := range r.Def.Fields {
+		fields = append(fields, fmt.Sprintf("%s: %s", name, snap[name].Inspect()))
+	}
+
+	out.WriteString(r.Def.Name)
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+type Class struct {
+	Name    string
+	Parent  *Class
+	Methods map[string]*Function
+}
+
+func (c *Class) Type() ObjectType { return CLASS_OBJECT }
+func (c *Class) Inspect() string  { return "sigma " + c.Name }
+
+// FindMethod looks up name through the parent chain
+// and returns the class that defines it
+func (c *Class) FindMethod(name string) (*Function, *Class) {
+	for cls := c; cls != nil; cls = cls.Parent {
+		if fn, ok := cls.Methods[name]; ok {
+			return fn, cls
+		}
+	}
+	return nil, nil
+}
+
+// NOTE:
+// like records, fields of a shared instance
+// are only touched through Get and Set
+type Instance struct {
+	mu         sync.RWMutex
+	Class      *Class
+	Fields     map[string]Object
+	inspect    func() string // set when the class defines inspect
+	inspecting atomic.Bool   // inspect is running for this instance
+}
+
+func (i *Instance) Get(name string) (Object, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	val, ok := i.Fields[name]
+	return val, ok
+}
+
+func (i *Instance) Set(name string, val Object) {
+	i.mu.Lock()
+	i.Fields[name] = val
+	i.mu.Unlock()
+}
+
+func (i *Instance) snapshot() map[string]Object {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return maps.Clone(i.Fields)
+}
+
+// SetInspect replaces how the instance is printed
+func (i *Instance) SetInspect(fn func() string) { i.inspect = fn }
+
+func (i *Instance) Type() ObjectType { return INSTANCE_OBJECT }
+func (i *Instance) Inspect() string {
+	// printing the instance again from its own inspect,
+	// directly or through others, gives the default format
+	if i.inspect != nil && i.inspecting.CompareAndSwap(false, true) {
+		defer i.inspecting.Store(false)
+		return i.inspect()
+	}
+
+	var out bytes.Buffer
+
+	snap := i.snapshot()
+	names := slices.Sorted(maps.Keys(snap))
+	fields := []string{}
+	for _, name := range names {
+		fields = append(fields, fmt.Sprintf("%s: %s", name, snap[name].Inspect()))
+	}
+
+	out.WriteString(i.Class.Name)
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// receiver seen through the methods of a parent class
+type Super struct {
+	Receiver *Instance
+	Class    *Class
+}
+
+func (s *Super) Type() ObjectType { return SUPER_OBJECT }
+func (s *Super) Inspect() string  { return "super " + s.Class.Name }
+
+// Equal reports whether a and b hold the same value,
+// comparing arrays, maps and sets element by element
+func Equal(a, b Object) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		return a.Value == b.(*Integer).Value
+	case *String:
+		return a.Value == b.(*String).Value
+	case *Boolean:
+		return a.Value == b.(*Boolean).Value
+	case *Null:
+		return true
+	case *Array:
+		other := b.(*Array)
+		if len(a.Elements) != len(other.Elements) {
+			return false
+		}
+		for i, elem := range a.Elements {
+			if !Equal(elem, other.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *Map:
+		other := b.(*Map)
+		if a.Len() != other.Len() {
+			return false
+		}
+		for _, pair := range a.Pairs() {
+			val, ok := other.Get(pair.Key)
+			if !ok || !Equal(pair.Value, val) {
+				return false
+			}
+		}
+		return true
+	case *Set:
+		other := b.(*Set)
+		if a.Len() != other.Len() {
+			return false
+		}
+		for _, elem := range a.Elements() {
+			if !other.Has(elem) {
+				return false
+			}
+		}
+		return true
+	case *Record:
+		other := b.(*Record)
+		if a.Def != other.Def {
+			return false
+		}
+		fields, others := a.snapshot(), other.snapshot()
+		for name, val := range fields {
+			if !Equal(val, others[name]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b // functions and builtins by identity
+	}
+}
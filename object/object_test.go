@@ -0,0 +1,140 @@
+package object
+
+import "testing"
+
+// collidingKey always hashes to the same value
+type collidingKey struct {
+	name string
+}
+
+func (c *collidingKey) Type() ObjectType { return "COLLIDING" }
+func (c *collidingKey) Inspect() string  { return c.name }
+func (c *collidingKey) Hash() Hash       { return Hash{Type: c.Type(), Value: 1} }
+
+func TestMapHashCollision(t *testing.T) {
+	a := &collidingKey{name: "a"}
+	b := &collidingKey{name: "b"}
+
+	mp := NewMap()
+	mp.Set(a, &Integer{Value: 1})
+	mp.Set(b, &Integer{Value: 2})
+
+	if mp.Len() != 2 {
+		t.Fatalf("mp.Len not equal to 2: got=%d", mp.Len())
+	}
+
+	for key, want := range map[Object]int{a: 1, b: 2} {
+		val, ok := mp.Get(key)
+		if !ok {
+			t.Errorf("no pair for %s", key.Inspect())
+			continue
+		}
+		if val.(*Integer).Value != want {
+			t.Errorf("val not equal to %d: got=%s", want, val.Inspect())
+		}
+	}
+
+	mp.Set(a, &Integer{Value: 3})
+	if val, _ := mp.Get(a); mp.Len() != 2 || val.(*Integer).Value != 3 {
+		t.Errorf("overwrite failed: len=%d val=%s", mp.Len(), val.Inspect())
+	}
+}
+
+func TestArrayHash(t *testing.T) {
+	a := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "x"}}}
+	b := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "x"}}}
+	c := &Array{Elements: []Object{&String{Value: "x"}, &Integer{Value: 1}}}
+
+	if a.Hash() != b.Hash() {
+		t.Errorf("equal arrays must share a hash")
+	}
+	if a.Hash() != a.Hash() {
+		t.Errorf("cached hash changed")
+	}
+	if a.Hash() == c.Hash() {
+		t.Errorf("element order must affect the hash")
+	}
+}
+
+func TestHashable(t *testing.T) {
+	tests := []struct {
+		got  Object
+		want bool
+	}{
+		{&Integer{Value: 1}, true},
+		{&String{Value: "a"}, true},
+		{&Boolean{Value: true}, true},
+		{&Array{Elements: []Object{&Integer{Value: 1}}}, true},
+		{&Array{Elements: []Object{&Array{}}}, true},
+		{&Array{Elements: []Object{NewMap()}}, false},
+		{NewMap(), false},
+		{&Null{}, false},
+	}
+
+	for _, tt := range tests {
+		if Hashable(tt.got) != tt.want {
+			t.Errorf("Hashable(%s) not equal to %t", tt.got.Inspect(), tt.want)
+		}
+	}
+}
+
+func TestMapPairsOrder(t *testing.T) {
+	mp := NewMap()
+	keys := []string{"c", "a", "b", "a"}
+	for i, key := range keys {
+		mp.Set(&String{Value: key}, &Integer{Value: i})
+	}
+
+	pairs := mp.Pairs()
+	want := []string{"c: 0", "a: 3", "b: 2"}
+	if len(pairs) != len(want) {
+		t.Fatalf("pairs must be %d statements: got=%d", len(want), len(pairs))
+	}
+	for i, pair := range pairs {
+		got := pair.Key.Inspect() + ": " + pair.Value.Inspect()
+		if got != want[i] {
+			t.Errorf("pairs[%d] not equal to %s: got=%s", i, want[i], got)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	set := NewSet()
+	for _, elem := range []int{3, 1, 3, 2} {
+		set.Add(&Integer{Value: elem})
+	}
+
+	if set.Len() != 3 {
+		t.Fatalf("set.Len not equal to 3: got=%d", set.Len())
+	}
+	if !set.Has(&Integer{Value: 2}) || set.Has(&Integer{Value: 4}) {
+		t.Errorf("set.Has wrong membership")
+	}
+	if set.Inspect() != "{3, 1, 2}" {
+		t.Errorf("set.Inspect not equal to {3, 1, 2}: got=%s", set.Inspect())
+	}
+	if NewSet().Inspect() != "set()" {
+		t.Errorf("empty set.Inspect not equal to set(): got=%s", NewSet().Inspect())
+	}
+}
+
+func TestLookupErrorKind(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, kind := range ErrorKinds {
+		if seen[kind.Code] {
+			t.Errorf("duplicate code %s", kind.Code)
+		}
+		seen[kind.Code] = true
+
+		for _, key := range []string{kind.Code, kind.Name} {
+			got, ok := LookupErrorKind(key)
+			if !ok || got != kind {
+				t.Errorf("LookupErrorKind(%s) not equal to %s: got=%v", key, kind.Code, got)
+			}
+		}
+	}
+
+	if _, ok := LookupErrorKind("E999"); ok {
+		t.Errorf("LookupErrorKind must fail for E999")
+	}
+}
@@ -16,27 +16,43 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	COALESCE    // ??
+	PIPE        // |>
 	EQUALS      // ==
-	LESSGREATER // < >
+	LESSGREATER // < > in
+	BITOR       // |
+	BITXOR      // ^
+	BITAND      // &
+	SHIFT       // << >>
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -x
 	CALL        // func(x)
-	INDEX       // x[y]
+	INDEX       // x[y] x.y
 )
 
 // associate types with precedences
 var precedences = map[token.TokenType]int{
+	token.COALESCE: COALESCE,
+	token.PIPE:     PIPE,
 	token.EQUAL:    EQUALS,
 	token.NOTEQUAL: EQUALS,
 	token.LESS:     LESSGREATER,
 	token.MORE:     LESSGREATER,
+	token.IN:       LESSGREATER,
+	token.BITOR:    BITOR,
+	token.BITXOR:   BITXOR,
+	token.BITAND:   BITAND,
+	token.LSHIFT:   SHIFT,
+	token.RSHIFT:   SHIFT,
 	token.PLUS:     SUM,
 	token.MINUS:    SUM,
 	token.MUL:      PRODUCT,
 	token.DIV:      PRODUCT,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
+	token.DOT:      INDEX,
+	token.OPTIONAL: INDEX,
 }
 
 type (
@@ -53,10 +69,16 @@ type Parser struct {
 
 	prefixFnMap map[token.TokenType]prefixFn
 	infixFnMap  map[token.TokenType]infixFn
+
+	// names declared per function scope, true if const
+	scopes []map[string]bool
+	// functions being parsed, innermost last
+	funcs []*ast.FunctionLiteral
 }
 
 func NewParser(l *lexer.Lexer) *Parser {
 	p := &Parser{l: l, err: []string{}}
+	p.scopes = []map[string]bool{make(map[string]bool)}
 
 	// register prefix functions to token types
 	p.prefixFnMap = make(map[token.TokenType]prefixFn)
@@ -65,6 +87,7 @@ func NewParser(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.NOT, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.BITNOT, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseParen)
@@ -73,6 +96,13 @@ func NewParser(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.FUNC, p.parseFunctionLiteral)
 	p.registerPrefix(token.LBRACE, p.parseMapLiteral)
 	p.registerPrefix(token.FOR, p.parseForExpression)
+	p.registerPrefix(token.YIELD, p.parseYieldExpression)
+	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
+	p.registerPrefix(token.SPAWN, p.parseSpawnExpression)
+	p.registerPrefix(token.ASYNC, p.parseAsyncFunctionLiteral)
+	p.registerPrefix(token.AWAIT, p.parseAwaitExpression)
+	p.registerPrefix(token.ELLIPSIS, p.parseSpreadExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
 
 	// register infix functions to token types
 	p.infixFnMap = make(map[token.TokenType]infixFn)
@@ -84,8 +114,18 @@ func NewParser(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.MORE, p.parseInfixExpression)
 	p.registerInfix(token.EQUAL, p.parseInfixExpression)
 	p.registerInfix(token.NOTEQUAL, p.parseInfixExpression)
+	p.registerInfix(token.IN, p.parseInfixExpression)
+	p.registerInfix(token.BITAND, p.parseInfixExpression)
+	p.registerInfix(token.BITOR, p.parseInfixExpression)
+	p.registerInfix(token.BITXOR, p.parseInfixExpression)
+	p.registerInfix(token.LSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.RSHIFT, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+	p.registerInfix(token.OPTIONAL, p.parseOptionalExpression)
+	p.registerInfix(token.COALESCE, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
 
 	p.NextToken()
 	p.NextToken()
@@ -121,10 +161,16 @@ func (p *Parser) Parse() *ast.Program {
 
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.RECORD:
+		return p.parseRecordStatement()
+	case token.CLASS:
+		return p.parseClassStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -135,12 +181,20 @@ func (p *Parser) parseStatement() ast.Statement {
 // both implement TokenLiteral() method, so ast.Statement
 // serves as a general interface for ast.LetStatement
 func (p *Parser) parseLetStatement() *ast.LetStatement {
-	stmt := &ast.LetStatement{Token: p.currToken}
+	stmt := &ast.LetStatement{Token: p.currToken, Const: p.currToken.Type == token.CONST}
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 
 	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	p.declare(stmt.Name.Value, stmt.Const)
+	if p.nxtToken.Type == token.COLON {
+		p.NextToken()
+		p.NextToken()
+		if stmt.Type = p.parseTypeAnnotation(); stmt.Type == nil {
+			return nil
+		}
+	}
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -166,10 +220,26 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+func (p *Parser) parseThrowStatement() ast.Statement {
+	stmt := &ast.ThrowStatement{Token: p.currToken}
+	p.NextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+	for p.nxtToken.Type == token.SEMICOLON {
+		p.NextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseExpressionStatement() ast.Statement {
 	stmt := &ast.ExpressionStatement{Token: p.currToken}
 	stmt.Expression = p.parseExpression(LOWEST)
 
+	if p.nxtToken.Type == token.ASSIGN {
+		return p.parseAssignStatement(stmt.Expression)
+	}
+
 	if p.nxtToken.Type == token.SEMICOLON {
 		p.NextToken()
 	}
@@ -177,6 +247,187 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	return stmt
 }
 
+// only fields can be assigned, names are rebound with let
+func (p *Parser) parseAssignStatement(target ast.Expression) ast.Statement {
+	if member, ok := target.(*ast.MemberExpression); !ok || member.Optional {
+		e := fmt.Sprintf("mid assign: %s", target)
+		p.err = append(p.err, e)
+		return nil
+	}
+
+	p.NextToken()
+	stmt := &ast.AssignStatement{Token: p.currToken, Target: target}
+
+	p.NextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	for p.nxtToken.Type == token.SEMICOLON {
+		p.NextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseRecordStatement() ast.Statement {
+	stmt := &ast.RecordStatement{Token: p.currToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	p.declare(stmt.Name.Value, false)
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for p.nxtToken.Type != token.RBRACE {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		field := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+		if seen[field.Value] {
+			e := fmt.Sprintf("mid field: %s", field.Value)
+			p.err = append(p.err, e)
+			return nil
+		}
+		seen[field.Value] = true
+		stmt.Fields = append(stmt.Fields, field)
+
+		if p.nxtToken.Type != token.RBRACE && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.NextToken()
+	for p.nxtToken.Type == token.SEMICOLON {
+		p.NextToken()
+	}
+
+	return stmt
+}
+
+// class <identifier> < <identifier> { func <identifier>(<arguments>) <body> }
+// operators a class can overload by naming a method after them
+var operatorMethods = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true,
+	"&": true, "|": true, "^": true, "<<": true, ">>": true,
+	"==": true, "<": true, ">": true, "[]": true,
+}
+
+// a method is named by an identifier, an operator or []
+func (p *Parser) parseMethodName() string {
+	switch {
+	case p.currToken.Type == token.IDENT:
+		return p.currToken.Literal
+	case p.currToken.Type == token.LBRACKET && p.nxtToken.Type == token.RBRACKET:
+		p.NextToken()
+		return "[]"
+	case operatorMethods[p.currToken.Literal] && p.currToken.Type != token.STRING:
+		return p.currToken.Literal
+	default:
+		e := fmt.Sprintf("mid method: %s", p.currToken.Literal)
+		p.err = append(p.err, e)
+		return ""
+	}
+}
+
+func (p *Parser) parseClassStatement() ast.Statement {
+	stmt := &ast.ClassStatement{Token: p.currToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	p.declare(stmt.Name.Value, false)
+	if p.nxtToken.Type == token.LESS {
+		p.NextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Parent = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for p.nxtToken.Type != token.RBRACE {
+		if !p.expectPeek(token.FUNC) {
+			return nil
+		}
+
+		method := &ast.FunctionLiteral{Token: p.currToken}
+		p.NextToken()
+		method.Name = p.parseMethodName()
+		if method.Name == "" {
+			return nil
+		}
+		if seen[method.Name] {
+			e := fmt.Sprintf("mid method: %s", method.Name)
+			p.err = append(p.err, e)
+			return nil
+		}
+		seen[method.Name] = true
+
+		if !p.parseFunction(method) {
+			return nil
+		}
+		// operator methods get the right operand or the index
+		if operatorMethods[method.Name] {
+			if len(method.Parameters) != 1 || len(method.Defaults) > 0 || method.Rest != nil {
+				e := fmt.Sprintf("mid param: %s", method.Name)
+				p.err = append(p.err, e)
+				return nil
+			}
+		}
+		stmt.Methods = append(stmt.Methods, method)
+
+		for p.nxtToken.Type == token.SEMICOLON {
+			p.NextToken()
+		}
+	}
+
+	p.NextToken()
+	for p.nxtToken.Type == token.SEMICOLON {
+		p.NextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.currToken, Left: left}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	exp.Property = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	return exp
+}
+
+// x?.y and x?.[y] mark the access as optional
+func (p *Parser) parseOptionalExpression(left ast.Expression) ast.Expression {
+	if p.nxtToken.Type != token.LBRACKET {
+		exp := p.parseMemberExpression(left)
+		if member, ok := exp.(*ast.MemberExpression); ok {
+			member.Optional = true
+		}
+		return exp
+	}
+
+	p.NextToken()
+	exp := p.parseIndexExpression(left)
+	switch exp := exp.(type) {
+	case *ast.IndexExpression:
+		exp.Optional = true
+	case *ast.SliceExpression:
+		exp.Optional = true
+	}
+	return exp
+}
+
 func (p *Parser) noPrefixFnError(t token.TokenType) {
 	e := fmt.Sprintf("hold this l: %s", t)
 	p.err = append(p.err, e)
@@ -213,17 +464,28 @@ func (p *Parser) parseIdentifier() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.currToken}
 
-	val, err := strconv.Atoi(p.currToken.Literal)
+	// base 0 reads 0x 0b 0o prefixes and _ separators,
+	// but leading zeros stay decimal instead of octal
+	digits := p.currToken.Literal
+	for len(digits) > 1 && digits[0] == '0' && isDigit(digits[1]) {
+		digits = digits[1:]
+	}
+
+	val, err := strconv.ParseInt(digits, 0, 0)
 	if err != nil {
 		e := fmt.Sprintf("sassy baka: %s", p.currToken.Literal)
 		p.err = append(p.err, e)
 		return nil
 	}
 
-	lit.Value = val
+	lit.Value = int(val)
 	return lit
 }
 
+func isDigit(char byte) bool {
+	return '0' <= char && char <= '9'
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal}
 }
@@ -354,59 +616,369 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	exp := &ast.FunctionLiteral{Token: p.currToken}
+	if !p.parseFunction(exp) {
+		return nil
+	}
 
-	if !p.expectPeek(token.LPAREN) {
+	return exp
+}
+
+// lowkey cook(...) { ... } may use holdup in its body
+func (p *Parser) parseAsyncFunctionLiteral() ast.Expression {
+	if !p.expectPeek(token.FUNC) {
 		return nil
 	}
-	exp.Parameters = p.parseFunctionArguments()
 
-	if !p.expectPeek(token.LBRACE) {
+	exp := &ast.FunctionLiteral{Token: p.currToken, Async: true}
+	if !p.parseFunction(exp) {
+		return nil
+	}
+
+	return exp
+}
+
+// holdup works at the top level or inside lowkey cook
+func (p *Parser) parseAwaitExpression() ast.Expression {
+	exp := &ast.AwaitExpression{Token: p.currToken}
+	if len(p.funcs) > 0 && !p.funcs[len(p.funcs)-1].Async {
+		p.err = append(p.err, "holdup outside lowkey cook")
 		return nil
 	}
-	exp.Body = p.parseBlockStatement()
 
+	p.NextToken()
+	exp.Value = p.parseExpression(PREFIX)
 	return exp
 }
 
-func (p *Parser) parseFunctionArguments() []*ast.Identifier {
-	idents := []*ast.Identifier{}
+// macros share function syntax but only take plain names
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	fn := &ast.FunctionLiteral{Token: p.currToken}
+	if !p.parseFunction(fn) {
+		return nil
+	}
+
+	if len(fn.Defaults) > 0 || fn.Rest != nil || len(fn.Types) > 0 || fn.Return != nil {
+		e := fmt.Sprintf("mid param: %s", fn.Token.Literal)
+		p.err = append(p.err, e)
+		return nil
+	}
+
+	return &ast.MacroLiteral{Token: fn.Token, Parameters: fn.Parameters, Body: fn.Body}
+}
+
+// parse (<arguments>) <body> or (<arguments>) => <expression> into fn
+func (p *Parser) parseFunction(fn *ast.FunctionLiteral) bool {
+	if !p.expectPeek(token.LPAREN) {
+		return false
+	}
+	if !p.parseFunctionArguments(fn) {
+		return false
+	}
+	if p.nxtToken.Type == token.COLON {
+		p.NextToken()
+		p.NextToken()
+		if fn.Return = p.parseTypeAnnotation(); fn.Return == nil {
+			return false
+		}
+	}
+
+	p.scopes = append(p.scopes, make(map[string]bool))
+	p.funcs = append(p.funcs, fn)
+	defer func() {
+		p.funcs = p.funcs[:len(p.funcs)-1]
+		p.scopes = p.scopes[:len(p.scopes)-1]
+	}()
+
+	// the arrow body is wrapped like cook(x) { rizz x; }
+	// so it runs and tail calls like any other function
+	if p.nxtToken.Type == token.ARROW {
+		p.NextToken()
+		ret := &ast.ReturnStatement{Token: p.currToken}
+		p.NextToken()
+		if ret.Value = p.parseExpression(LOWEST); ret.Value == nil {
+			return false
+		}
+
+		fn.Arrow = true
+		fn.Body = &ast.BlockStatement{Token: ret.Token, Statements: []ast.Statement{ret}}
+		return true
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return false
+	}
+	fn.Body = p.parseBlockStatement()
+	return true
+}
+
+// NOTE:
+// consts are caught here when declared in the same
+// source, evaluator checks the rest at runtime
+func (p *Parser) declare(name string, constant bool) {
+	scope := p.scopes[len(p.scopes)-1]
+	if scope[name] {
+		e := fmt.Sprintf("nocap: %s", name)
+		p.err = append(p.err, e)
+		return
+	}
+
+	scope[name] = constant
+}
+
+// parse (a, b = <expression>, ...rest) into fn
+func (p *Parser) parseFunctionArguments(fn *ast.FunctionLiteral) bool {
+	fn.Parameters = []*ast.Identifier{}
+	fn.Defaults = make(map[string]ast.Expression)
+	fn.Types = make(map[string]*ast.TypeAnnotation)
 	if p.nxtToken.Type == token.RPAREN {
 		p.NextToken()
-		return idents
+		return true
+	}
+
+	for {
+		p.NextToken()
+		if p.currToken.Type == token.ELLIPSIS {
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+			fn.Rest = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+			if !p.parseParameterType(fn, fn.Rest) {
+				return false
+			}
+			break // rest must be the last parameter
+		}
+
+		if p.currToken.Type != token.IDENT {
+			e := fmt.Sprintf("mid param: %s", p.currToken.Literal)
+			p.err = append(p.err, e)
+			return false
+		}
+		ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+		fn.Parameters = append(fn.Parameters, ident)
+		if !p.parseParameterType(fn, ident) {
+			return false
+		}
+
+		if p.nxtToken.Type == token.ASSIGN {
+			p.NextToken()
+			p.NextToken()
+			fn.Defaults[ident.Value] = p.parseExpression(LOWEST)
+		} else if len(fn.Defaults) > 0 {
+			e := fmt.Sprintf("mid param: %s after default", ident.Value)
+			p.err = append(p.err, e)
+			return false
+		}
+
+		if p.nxtToken.Type != token.COMMA {
+			break
+		}
+		p.NextToken()
 	}
 
+	return p.expectPeek(token.RPAREN)
+}
+
+// optional : <type> after a parameter
+func (p *Parser) parseParameterType(fn *ast.FunctionLiteral, param *ast.Identifier) bool {
+	if p.nxtToken.Type != token.COLON {
+		return true
+	}
+	p.NextToken()
 	p.NextToken()
-	ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
-	idents = append(idents, ident)
 
-	for p.nxtToken.Type == token.COMMA {
+	typ := p.parseTypeAnnotation()
+	if typ == nil {
+		return false
+	}
+	fn.Types[param.Value] = typ
+	return true
+}
+
+// construct a type with current token on its name
+func (p *Parser) parseTypeAnnotation() *ast.TypeAnnotation {
+	typ := &ast.TypeAnnotation{Token: p.currToken, Name: p.currToken.Literal}
+
+	switch {
+	case p.currToken.Type == token.IDENT && p.nxtToken.Type == token.LBRACKET:
 		p.NextToken()
+		if typ.Params = p.parseTypeList(token.RBRACKET); typ.Params == nil {
+			return nil
+		}
+		if len(typ.Params) == 0 {
+			e := fmt.Sprintf("mid type: %s[]", typ.Name)
+			p.err = append(p.err, e)
+			return nil
+		}
+	case p.currToken.Type == token.FUNC && p.nxtToken.Type == token.LPAREN:
 		p.NextToken()
-		idents = append(idents, &ast.Identifier{
-			Token: p.currToken,
-			Value: p.currToken.Literal,
-		})
+		if typ.Params = p.parseTypeList(token.RPAREN); typ.Params == nil {
+			return nil
+		}
+		if p.nxtToken.Type == token.COLON {
+			p.NextToken()
+			p.NextToken()
+			if typ.Return = p.parseTypeAnnotation(); typ.Return == nil {
+				return nil
+			}
+		}
+	case p.currToken.Type != token.IDENT && p.currToken.Type != token.FUNC:
+		e := fmt.Sprintf("mid type: %s", p.currToken.Literal)
+		p.err = append(p.err, e)
+		return nil
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
+	return typ
+}
+
+// like parseExpressionList but for types, nil on errors
+func (p *Parser) parseTypeList(end token.TokenType) []*ast.TypeAnnotation {
+	list := []*ast.TypeAnnotation{}
+	if p.nxtToken.Type == end {
+		p.NextToken()
+		return list
+	}
+
+	for {
+		p.NextToken()
+		typ := p.parseTypeAnnotation()
+		if typ == nil {
+			return nil
+		}
+		list = append(list, typ)
+
+		if p.nxtToken.Type != token.COMMA {
+			break
+		}
+		p.NextToken()
 	}
 
-	return idents
+	if !p.expectPeek(end) {
+		return nil
+	}
+	return list
 }
 
 func (p *Parser) parseCallExpression(fn ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.currToken, Function: fn}
-	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	exp.Arguments = p.parseCallArguments()
+	return exp
+}
+
+// x |> f(a) becomes f(x, a) and x |> f becomes f(x)
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	tok := p.currToken
+	p.NextToken()
+	right := p.parseExpression(PIPE)
+	if right == nil {
+		return nil
+	}
+
+	if call, ok := right.(*ast.CallExpression); ok && !call.Piped {
+		call.Arguments = append([]ast.Expression{left}, call.Arguments...)
+		call.Piped = true
+		return call
+	}
+	return &ast.CallExpression{Token: tok, Function: right, Arguments: []ast.Expression{left}, Piped: true}
+}
+
+// like parseExpressionList but allows <identifier> = <expression>
+func (p *Parser) parseCallArguments() []ast.Expression {
+	list := []ast.Expression{}
+	if p.nxtToken.Type == token.RPAREN {
+		p.NextToken()
+		return nil
+	}
+
+	for {
+		p.NextToken()
+		if p.currToken.Type == token.IDENT && p.nxtToken.Type == token.ASSIGN {
+			arg := &ast.NamedArgument{
+				Name: &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal},
+			}
+			p.NextToken()
+			arg.Token = p.currToken
+
+			p.NextToken()
+			arg.Value = p.parseExpression(LOWEST)
+			list = append(list, arg)
+		} else {
+			list = append(list, p.parseExpression(LOWEST))
+		}
+
+		if p.nxtToken.Type != token.COMMA {
+			break
+		}
+		p.NextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return list
+}
+
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	exp := &ast.SpreadExpression{Token: p.currToken}
+	p.NextToken()
+	exp.Value = p.parseExpression(PREFIX)
 	return exp
 }
 
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	arr := &ast.ArrayLiteral{Token: p.currToken}
-	arr.Elements = p.parseExpressionList(token.RBRACKET)
+	if p.nxtToken.Type == token.RBRACKET {
+		p.NextToken()
+		return arr
+	}
+
+	p.NextToken()
+	first := p.parseExpression(LOWEST)
+	if p.nxtToken.Type == token.FOR {
+		return p.parseComprehension(arr.Token, nil, first, token.RBRACKET)
+	}
+
+	arr.Elements = []ast.Expression{first}
+	for p.nxtToken.Type == token.COMMA {
+		p.NextToken()
+		p.NextToken()
+		arr.Elements = append(arr.Elements, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
 	return arr
 }
 
+// construct the rest of a comprehension with current token
+// on its value, the variable only lives inside it
+func (p *Parser) parseComprehension(tok token.Token, key, value ast.Expression, end token.TokenType) ast.Expression {
+	exp := &ast.Comprehension{Token: tok, Key: key, Value: value}
+	p.NextToken()
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Variable = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+	p.NextToken()
+	exp.Iterable = p.parseExpression(LOWEST)
+
+	if p.nxtToken.Type == token.IF {
+		p.NextToken()
+		p.NextToken()
+		exp.Condition = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+	return exp
+}
+
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	list := []ast.Expression{}
 	if p.nxtToken.Type == end {
@@ -432,7 +1004,40 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.currToken, Left: left}
 	p.NextToken()
 
+	if p.currToken.Type == token.COLON {
+		return p.parseSliceExpression(exp.Token, left, nil)
+	}
+
 	exp.Index = p.parseExpression(LOWEST)
+	if p.nxtToken.Type == token.COLON {
+		p.NextToken()
+		return p.parseSliceExpression(exp.Token, left, exp.Index)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+// construct x[start:end:step] with current token on the first colon
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+
+	if p.nxtToken.Type != token.COLON && p.nxtToken.Type != token.RBRACKET {
+		p.NextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
+
+	if p.nxtToken.Type == token.COLON {
+		p.NextToken()
+		if p.nxtToken.Type != token.RBRACKET {
+			p.NextToken()
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
+
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
@@ -448,12 +1053,21 @@ func (p *Parser) parseMapLiteral() ast.Expression {
 		p.NextToken()
 		key := p.parseExpression(LOWEST)
 
+		// no colon after the first key makes it a set
+		if len(mp.Keys) == 0 && p.nxtToken.Type != token.COLON {
+			return p.parseSetLiteral(mp.Token, key)
+		}
+
 		if !p.expectPeek(token.COLON) {
 			return nil
 		}
 
 		p.NextToken()
 		val := p.parseExpression(LOWEST)
+		if len(mp.Keys) == 0 && p.nxtToken.Type == token.FOR {
+			return p.parseComprehension(mp.Token, key, val, token.RBRACE)
+		}
+		mp.Keys = append(mp.Keys, key)
 		mp.Pairs[key] = val
 
 		if p.nxtToken.Literal != token.RBRACE && !p.expectPeek(token.COMMA) {
@@ -468,6 +1082,30 @@ func (p *Parser) parseMapLiteral() ast.Expression {
 	return mp
 }
 
+// NOTE: {} stays an empty map, sets start from set()
+func (p *Parser) parseSetLiteral(tok token.Token, first ast.Expression) ast.Expression {
+	if p.nxtToken.Type == token.FOR {
+		return p.parseComprehension(tok, nil, first, token.RBRACE)
+	}
+
+	set := &ast.SetLiteral{Token: tok, Elements: []ast.Expression{first}}
+
+	for p.nxtToken.Type == token.COMMA {
+		p.NextToken()
+		if p.nxtToken.Type == token.RBRACE {
+			break
+		}
+		p.NextToken()
+		set.Elements = append(set.Elements, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return set
+}
+
 func (p *Parser) parseForExpression() ast.Expression {
 	fl := &ast.ForExpression{Token: p.currToken}
 	if !p.expectPeek(token.LPAREN) {
@@ -475,6 +1113,10 @@ func (p *Parser) parseForExpression() ast.Expression {
 	}
 
 	p.NextToken()
+	if p.currToken.Type == token.IDENT && p.nxtToken.Type == token.IN {
+		return p.parseForEachExpression(fl.Token)
+	}
+
 	fl.Condition = p.parseExpression(LOWEST)
 	if !p.expectPeek(token.RPAREN) {
 		return nil
@@ -485,4 +1127,96 @@ func (p *Parser) parseForExpression() ast.Expression {
 	}
 	fl.Body = p.parseBlockStatement()
 	return fl
-}
\ No newline at end of file
+}
+
+// slay turns the enclosing function into a generator
+func (p *Parser) parseYieldExpression() ast.Expression {
+	exp := &ast.YieldExpression{Token: p.currToken}
+	if len(p.funcs) == 0 {
+		p.err = append(p.err, "slay outside cook")
+		return nil
+	}
+	p.funcs[len(p.funcs)-1].Generator = true
+
+	p.NextToken()
+	exp.Value = p.parseExpression(LOWEST)
+	return exp
+}
+
+// sendit only takes a call, f(x) runs on its own task
+func (p *Parser) parseSpawnExpression() ast.Expression {
+	exp := &ast.SpawnExpression{Token: p.currToken}
+
+	p.NextToken()
+	call, ok := p.parseExpression(PREFIX).(*ast.CallExpression)
+	if !ok {
+		e := fmt.Sprintf("mid sendit: want call, got %s", p.currToken.Literal)
+		p.err = append(p.err, e)
+		return nil
+	}
+
+	exp.Call = call
+	return exp
+}
+
+// mew (x in xs) binds x to each element in turn
+func (p *Parser) parseForEachExpression(tok token.Token) ast.Expression {
+	fe := &ast.ForEachExpression{Token: tok}
+	fe.Variable = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	p.declare(fe.Variable.Value, false)
+
+	p.NextToken()
+	p.NextToken()
+	fe.Iterable = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	fe.Body = p.parseBlockStatement()
+	return fe
+}
+
+func (p *Parser) parseTryExpression() ast.Expression {
+	exp := &ast.TryExpression{Token: p.currToken}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	exp.Block = p.parseBlockStatement()
+
+	if p.nxtToken.Type == token.CATCH {
+		p.NextToken()
+		if p.nxtToken.Type == token.LPAREN {
+			p.NextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			exp.Param = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		exp.Catch = p.parseBlockStatement()
+	}
+
+	if p.nxtToken.Type == token.FINALLY {
+		p.NextToken()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		exp.Finally = p.parseBlockStatement()
+	}
+
+	if exp.Catch == nil && exp.Finally == nil {
+		p.peekError(token.CATCH)
+		return nil
+	}
+
+	return exp
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/dxtym/skibidi/ast"
@@ -390,6 +391,10 @@ func TestOperatorPrecedence(t *testing.T) {
 			"3 > 5 == cap",
 			"((3 > 5) == cap)",
 		},
+		{
+			"a + 1 in xs == fax",
+			"(((a + 1) in xs) == fax)",
+		},
 		{
 			"1 + (2 + 3) + 4",
 			"((1 + (2 + 3)) + 4)",
@@ -410,6 +415,30 @@ func TestOperatorPrecedence(t *testing.T) {
 			"3 + 4 * -5 != 3 * -1 + 4 * 5",
 			"((3 + (4 * (-5))) != ((3 * (-1)) + (4 * 5)))",
 		},
+		{
+			"a | b ^ c & d << 1 + 2",
+			"(a | (b ^ (c & (d << (1 + 2)))))",
+		},
+		{
+			"a & b == c >> 1",
+			"((a & b) == (c >> 1))",
+		},
+		{
+			"~a << b >> c",
+			"(((~a) << b) >> c)",
+		},
+		{
+			"add(a, b * c, d(e))",
+			"add(a, (b * c), d(e))",
+		},
+		{
+			"a + 1 |> f(b) |> g",
+			"(((a + 1) |> f(b)) |> g)",
+		},
+		{
+			"a |> b < c",
+			"(a |> (b < c))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -425,6 +454,52 @@ func TestOperatorPrecedence(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		got  string
+		want int
+	}{
+		{"0x1F", 31},
+		{"0XfF", 255},
+		{"0b1010", 10},
+		{"0o17", 15},
+		{"1_000_000", 1000000},
+		{"0xff_ff", 65535},
+		{"007", 7},
+		{"0", 0},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression not *ast.IntegerLiteral: got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.want {
+			t.Errorf("literal.Value not equal to %d: got=%d", tt.want, literal.Value)
+		}
+		// the source spelling is kept for printing
+		if literal.TokenLiteral() != tt.got {
+			t.Errorf("literal.TokenLiteral not equal to %s: got=%s", tt.got, literal.TokenLiteral())
+		}
+	}
+
+	for _, tt := range []string{"0xz", "0b2", "1__0", "1_", "12ab"} {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
 func TestBoolean(t *testing.T) {
 	tests := []struct {
 		got  string
@@ -716,6 +791,10 @@ func TestMapLiteral(t *testing.T) {
 		t.Errorf("mp.Pairs must be 2 statements: got=%d", len(mp.Pairs))
 	}
 
+	if mp.String() != "{foo: bar, baz: qux}" {
+		t.Errorf("mp.String not equal to %s: got=%s", "{foo: bar, baz: qux}", mp.String())
+	}
+
 	want := map[string]string{"foo": "bar", "baz": "qux"}
 	for key, val := range mp.Pairs {
 		literal, ok := key.(*ast.StringLiteral)
@@ -762,4 +841,772 @@ func TestForExpression(t *testing.T) {
 	if !ok {
 		t.Errorf("loop.Body.Statements[0] not *ast.ReturnStatement: got=%T", loop.Body.Statements[0])
 	}
-}
\ No newline at end of file
+}
+
+func TestFunctionDefaultAndRestParameters(t *testing.T) {
+	got := "cook(x, y = 2, ...z) {}"
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+	fn, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression not *ast.FunctionLiteral: got=%T", stmt.Expression)
+	}
+
+	if len(fn.Parameters) != 2 {
+		t.Errorf("fn.Parameters must be 2 statements: got=%d", len(fn.Parameters))
+	}
+	testLiteralExpression(t, fn.Parameters[0], "x")
+	testLiteralExpression(t, fn.Parameters[1], "y")
+
+	if _, ok := fn.Defaults["x"]; ok {
+		t.Errorf("fn.Defaults must not contain x")
+	}
+	testIntegerLiteral(t, fn.Defaults["y"], 2)
+
+	if fn.Rest == nil || fn.Rest.Value != "z" {
+		t.Errorf("fn.Rest not equal to z: got=%v", fn.Rest)
+	}
+	if fn.String() != "cook(x,y = 2,...z)" {
+		t.Errorf("fn.String not equal to %s: got=%s", "cook(x,y = 2,...z)", fn.String())
+	}
+}
+
+func TestFunctionParameterErrors(t *testing.T) {
+	tests := []string{
+		"cook(x = 1, y) {}",
+		"cook(...x, y) {}",
+		"cook(1) {}",
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
+func TestCallNamedAndSpreadArguments(t *testing.T) {
+	got := "do(1, ...xs, y = 2);"
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression not *ast.CallExpression: got=%T", stmt.Expression)
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("exp.Arguments must be 3 statements: got=%d", len(exp.Arguments))
+	}
+	testIntegerLiteral(t, exp.Arguments[0], 1)
+
+	spread, ok := exp.Arguments[1].(*ast.SpreadExpression)
+	if !ok {
+		t.Fatalf("exp.Arguments[1] not *ast.SpreadExpression: got=%T", exp.Arguments[1])
+	}
+	testIdentifier(t, spread.Value, "xs")
+
+	named, ok := exp.Arguments[2].(*ast.NamedArgument)
+	if !ok {
+		t.Fatalf("exp.Arguments[2] not *ast.NamedArgument: got=%T", exp.Arguments[2])
+	}
+	testIdentifier(t, named.Name, "y")
+	testIntegerLiteral(t, named.Value, 2)
+}
+
+func TestSliceExpression(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"arr[1:2]", "(arr[1:2])"},
+		{"arr[:2]", "(arr[:2])"},
+		{"arr[1:]", "(arr[1:])"},
+		{"arr[:]", "(arr[:])"},
+		{"arr[::2]", "(arr[::2])"},
+		{"arr[1:2:3]", "(arr[1:2:3])"},
+		{"arr[a + 1:-1]", "(arr[(a + 1):(-1)])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.SliceExpression); !ok {
+			t.Errorf("stmt.Expression not *ast.SliceExpression: got=%T", stmt.Expression)
+		}
+		if program.String() != tt.want {
+			t.Errorf("program.String not equal to %s: got=%s", tt.want, program.String())
+		}
+	}
+}
+
+func TestRecordStatement(t *testing.T) {
+	got := "squad Point { x, y };"
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements must be 1 statement: got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.RecordStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] not *ast.RecordStatement: got=%T", program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Name, "Point")
+	if len(stmt.Fields) != 2 {
+		t.Fatalf("stmt.Fields must be 2 statements: got=%d", len(stmt.Fields))
+	}
+	testIdentifier(t, stmt.Fields[0], "x")
+	testIdentifier(t, stmt.Fields[1], "y")
+
+	if stmt.String() != "squad Point {x, y};" {
+		t.Errorf("stmt.String not equal to %s: got=%s", "squad Point {x, y};", stmt.String())
+	}
+}
+
+func TestMemberAndAssignStatement(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"p.x", "(p.x)"},
+		{"p.x.y", "((p.x).y)"},
+		{"p.x + 1", "((p.x) + 1)"},
+		{"-p.x", "(-(p.x))"},
+		{"ps[0].x", "((ps[0]).x)"},
+		{"p.x = 1 + 2;", "(p.x) = (1 + 2);"},
+		{"p.x.y = q.z;", "((p.x).y) = (q.z);"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		if program.String() != tt.want {
+			t.Errorf("program.String not equal to %s: got=%s", tt.want, program.String())
+		}
+	}
+
+	for _, tt := range []string{"x = 1;", "squad P { x, x };", "p.1"} {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
+func TestComprehension(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+		key  bool
+		cond bool
+	}{
+		{"[x * 2 mew x in xs hawk x > 1]", "[(x * 2) mew x in xs hawk (x > 1)]", false, true},
+		{"[x mew x in f(xs)]", "[x mew x in f(xs)]", false, false},
+		{"{x mew x in xs}", "{x mew x in xs}", false, false},
+		{"{x: x * x mew x in xs hawk x}", "{x: (x * x) mew x in xs hawk x}", true, true},
+		{"[[y mew y in x] mew x in xs]", "[[y mew y in x] mew x in xs]", false, false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.Comprehension)
+		if !ok {
+			t.Fatalf("stmt.Expression not *ast.Comprehension: got=%T", stmt.Expression)
+		}
+		if (exp.Key != nil) != tt.key || (exp.Condition != nil) != tt.cond {
+			t.Errorf("wrong parts for %s: got=%s", tt.got, exp)
+		}
+		if exp.String() != tt.want {
+			t.Errorf("exp.String not equal to %s: got=%s", tt.want, exp.String())
+		}
+	}
+
+	for _, tt := range []string{"[x mew x xs]", "[x mew 1 in xs]", "[x mew x in xs hawk]", "[x, y mew x in xs]", "{x: 1, y: 2 mew x in xs}"} {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
+func TestOptionalAndCoalesceExpression(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"a?.b", "(a?.b)"},
+		{"a?.b.c", "((a?.b).c)"},
+		{"a?.[0]", "(a?.[0])"},
+		{"a?.[1:]", "(a?.[1:])"},
+		{"a?.b?.[k]", "((a?.b)?.[k])"},
+		{"a ?? b", "(a ?? b)"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"a?.b ?? 1 + 2", "((a?.b) ?? (1 + 2))"},
+		{"a ?? b == c", "(a ?? (b == c))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		if program.String() != tt.want {
+			t.Errorf("program.String not equal to %s: got=%s", tt.want, program.String())
+		}
+	}
+
+	for _, tt := range []string{"a?.b = 1;", "a?.1", "a?."} {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
+func TestYieldExpression(t *testing.T) {
+	got := "cook() { slay 1; cook() { 2 }; }; cook() { cook() { slay 3 } }"
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	outer := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if !outer.Generator {
+		t.Errorf("outer.Generator not true")
+	}
+	inner := outer.Body.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if inner.Generator {
+		t.Errorf("inner.Generator not false")
+	}
+
+	// only the innermost function becomes a generator
+	outer = program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if outer.Generator {
+		t.Errorf("outer.Generator not false")
+	}
+	inner = outer.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if !inner.Generator {
+		t.Errorf("inner.Generator not true")
+	}
+	if inner.Body.String() != "slay 3" {
+		t.Errorf("inner.Body.String not equal to %s: got=%s", "slay 3", inner.Body.String())
+	}
+
+	l = lexer.NewLexer("slay 1;")
+	p = NewParser(l)
+	p.Parse()
+	if len(p.Errors()) == 0 {
+		t.Errorf("parser must fail for slay outside cook")
+	}
+}
+
+func TestMacroLiteral(t *testing.T) {
+	got := "cheat(x, y) { x + y; }"
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+	macro, ok := stmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression not *ast.MacroLiteral: got=%T", stmt.Expression)
+	}
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("macro.Parameters must be 2 parameters: got=%d", len(macro.Parameters))
+	}
+	testIdentifier(t, macro.Parameters[0], "x")
+	testIdentifier(t, macro.Parameters[1], "y")
+	if macro.Body.String() != "(x + y)" {
+		t.Errorf("macro.Body.String not equal to %s: got=%s", "(x + y)", macro.Body.String())
+	}
+
+	for _, tt := range []string{"cheat(x = 1) { x }", "cheat(...xs) { xs }"} {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
+func TestSpawnExpression(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"sendit f(1)", "f"},
+		{"sendit obj.run()", "(obj.run)"},
+		{"sendit fs[0](x)", "(fs[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.SpawnExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression not *ast.SpawnExpression: got=%T", stmt.Expression)
+		}
+		if exp.Call.Function.String() != tt.want {
+			t.Errorf("exp.Call.Function.String not equal to %s: got=%s", tt.want, exp.Call.Function.String())
+		}
+	}
+
+	for _, tt := range []string{"sendit 1", "sendit f", "sendit (f)"} {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
+func TestArrowFunction(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"cook(x) => x * 2", "cook(x) => (x * 2)"},
+		{"cook() => 1", "cook() => 1"},
+		{"cook(a, b = 1, ...r) => f(a, b)", "cook(a,b = 1,...r) => f(a, b)"},
+		{"cook(a) => cook(b) => a + b", "cook(a) => cook(b) => (a + b)"},
+		{"lowkey cook(x) => holdup x", "lowkey cook(x) => (holdup x)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+		fn, ok := stmt.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression not *ast.FunctionLiteral: got=%T", stmt.Expression)
+		}
+		if !fn.Arrow {
+			t.Errorf("fn.Arrow not true for %s", tt.got)
+		}
+		if len(fn.Body.Statements) != 1 {
+			t.Fatalf("fn.Body.Statements must be 1 statement: got=%d", len(fn.Body.Statements))
+		}
+		if _, ok := fn.Body.Statements[0].(*ast.ReturnStatement); !ok {
+			t.Errorf("fn.Body.Statements[0] not *ast.ReturnStatement: got=%T", fn.Body.Statements[0])
+		}
+		if fn.String() != tt.want {
+			t.Errorf("fn.String not equal to %s: got=%s", tt.want, fn.String())
+		}
+	}
+
+	for _, tt := range []string{"cook(x) =>", "cook(x) => rizz x", "x => x"} {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"amogus x: int = 1;", "amogus x: int = 1;"},
+		{"nocap xs: array[int] = [];", "nocap xs: array[int] = [];"},
+		{"amogus m: map[string, array[int]] = {};", "amogus m: map[string, array[int]] = {};"},
+		{"cook(a: int, b: string = \"x\", ...r: bool): int { a }", "cook(a: int,b: string = x,...r: bool): inta"},
+		{"cook(f: cook(int, cook): cook(): int) => f", "cook(f: cook(int, cook): cook(): int) => f"},
+		{"lowkey cook(x: int): int => holdup x", "lowkey cook(x: int): int => (holdup x)"},
+		{"{x: 1}", "{x: 1}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		if program.String() != tt.want {
+			t.Errorf("program.String not equal to %s: got=%s", tt.want, program.String())
+		}
+	}
+
+	for _, tt := range []string{"amogus x: = 1;", "amogus x: 1 = 1;", "amogus x: array[] = 1;", "cook(x:) {}", "cook(): {}", "cheat(x: int) { x }"} {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
+func TestAsyncFunctionAndAwait(t *testing.T) {
+	got := "lowkey cook(x) { holdup x }"
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+	fn, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression not *ast.FunctionLiteral: got=%T", stmt.Expression)
+	}
+	if !fn.Async {
+		t.Errorf("fn.Async not true")
+	}
+
+	body, _ := fn.Body.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := body.Expression.(*ast.AwaitExpression)
+	if !ok {
+		t.Fatalf("body.Expression not *ast.AwaitExpression: got=%T", body.Expression)
+	}
+	testIdentifier(t, exp.Value, "x")
+
+	tests := []struct {
+		got  string
+		fail bool
+	}{
+		{"holdup x", false},
+		{"lowkey cook() { cook() { holdup x } }", true},
+		{"cook() { holdup x }", true},
+		{"lowkey 1", true},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		p.Parse()
+
+		if (len(p.Errors()) > 0) != tt.fail {
+			t.Errorf("parser errors for %s: got=%v", tt.got, p.Errors())
+		}
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []struct {
+		got  string
+		fn   string
+		args []string
+	}{
+		{"x |> f", "f", []string{"x"}},
+		{"x |> f()", "f", []string{"x"}},
+		{"x |> f(a, b = 1)", "f", []string{"x", "a", "b = 1"}},
+		{"x |> obj.f(a)", "(obj.f)", []string{"x", "a"}},
+		{"x |> f |> g(a)", "g", []string{"(x |> f)", "a"}},
+		{"x |> (y |> f)", "(y |> f)", []string{"x"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.CallExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression not *ast.CallExpression: got=%T", stmt.Expression)
+		}
+		if !exp.Piped {
+			t.Errorf("exp.Piped not true for %s", tt.got)
+		}
+		if exp.Function.String() != tt.fn {
+			t.Errorf("exp.Function.String not equal to %s: got=%s", tt.fn, exp.Function.String())
+		}
+
+		args := []string{}
+		for _, arg := range exp.Arguments {
+			args = append(args, arg.String())
+		}
+		if strings.Join(args, "; ") != strings.Join(tt.args, "; ") {
+			t.Errorf("exp.Arguments not equal to %v: got=%v", tt.args, args)
+		}
+	}
+
+	l := lexer.NewLexer("x |>")
+	p := NewParser(l)
+	p.Parse()
+	if len(p.Errors()) == 0 {
+		t.Errorf("parser must fail without a function")
+	}
+}
+
+func TestClassStatement(t *testing.T) {
+	got := `sigma Dog < Animal {
+		cook init(name) { self.name = name; }
+		cook speak() { rizz super.speak(); }
+	}`
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements must be 1 statement: got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ClassStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] not *ast.ClassStatement: got=%T", program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Name, "Dog")
+	testIdentifier(t, stmt.Parent, "Animal")
+
+	if len(stmt.Methods) != 2 {
+		t.Fatalf("stmt.Methods must be 2 statements: got=%d", len(stmt.Methods))
+	}
+	if stmt.Methods[0].Name != "init" || len(stmt.Methods[0].Parameters) != 1 {
+		t.Errorf("stmt.Methods[0] not init(name): got=%s", stmt.Methods[0])
+	}
+	if stmt.Methods[1].Name != "speak" || len(stmt.Methods[1].Parameters) != 0 {
+		t.Errorf("stmt.Methods[1] not speak(): got=%s", stmt.Methods[1])
+	}
+
+	l = lexer.NewLexer("sigma V { cook +(o) { o } cook ==(o) => fax cook [](i) { i } cook <<(n) => n }")
+	p = NewParser(l)
+	program = p.Parse()
+	checkParser(t, p)
+
+	stmt = program.Statements[0].(*ast.ClassStatement)
+	names := []string{}
+	for _, m := range stmt.Methods {
+		names = append(names, m.Name)
+	}
+	if strings.Join(names, " ") != "+ == [] <<" {
+		t.Errorf("method names not equal to + == [] <<: got=%v", names)
+	}
+
+	for _, tt := range []string{
+		"sigma A { x }", "sigma A { cook f() {} cook f() {} }", "sigma A < { }",
+		"sigma A { cook +() {} }", "sigma A { cook +(a, b) {} }", "sigma A { cook [](i = 1) {} }",
+		"sigma A { cook !(o) {} }", "sigma A { cook [(o) {} }",
+	} {
+		l := lexer.NewLexer(tt)
+		p := NewParser(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser must fail for %s", tt)
+		}
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	got := "sus { yeet 1; } cope (e) { e } periodt { 2 }"
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.TryExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression not *ast.TryExpression: got=%T", stmt.Expression)
+	}
+
+	if len(exp.Block.Statements) != 1 {
+		t.Fatalf("exp.Block.Statements must be 1 statement: got=%d", len(exp.Block.Statements))
+	}
+	throw, ok := exp.Block.Statements[0].(*ast.ThrowStatement)
+	if !ok {
+		t.Fatalf("exp.Block.Statements[0] not *ast.ThrowStatement: got=%T", exp.Block.Statements[0])
+	}
+	testIntegerLiteral(t, throw.Value, 1)
+
+	testIdentifier(t, exp.Param, "e")
+	if exp.Catch == nil || len(exp.Catch.Statements) != 1 {
+		t.Errorf("exp.Catch must be 1 statement: got=%v", exp.Catch)
+	}
+	if exp.Finally == nil || len(exp.Finally.Statements) != 1 {
+		t.Errorf("exp.Finally must be 1 statement: got=%v", exp.Finally)
+	}
+
+	tests := []struct {
+		got   string
+		param bool
+		catch bool
+		fin   bool
+		want  string
+	}{
+		{"sus { 1 } cope { 2 }", false, true, false, "sus{1}cope{2}"},
+		{"sus { 1 } periodt { 2 }", false, false, true, "sus{1}periodt{2}"},
+		{"sus { 1 } cope (e) { 2 }", true, true, false, "sus{1}cope(e){2}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+		exp := stmt.Expression.(*ast.TryExpression)
+		if (exp.Param != nil) != tt.param || (exp.Catch != nil) != tt.catch || (exp.Finally != nil) != tt.fin {
+			t.Errorf("wrong clauses for %s: got=%s", tt.got, exp)
+		}
+		if exp.String() != tt.want {
+			t.Errorf("exp.String not equal to %s: got=%s", tt.want, exp.String())
+		}
+	}
+
+	l = lexer.NewLexer("sus { 1 }")
+	p = NewParser(l)
+	p.Parse()
+	if len(p.Errors()) == 0 {
+		t.Errorf("parser must fail without cope or periodt")
+	}
+}
+
+func TestConstStatement(t *testing.T) {
+	got := "nocap x = 1;"
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] not *ast.LetStatement: got=%T", program.Statements[0])
+	}
+	testIdentifier(t, stmt.Name, "x")
+	if !stmt.Const {
+		t.Errorf("stmt.Const not true")
+	}
+	if stmt.String() != "nocap x = 1;" {
+		t.Errorf("stmt.String not equal to %s: got=%s", "nocap x = 1;", stmt.String())
+	}
+
+	tests := []struct {
+		got  string
+		fail bool
+	}{
+		{"nocap x = 1; amogus x = 2;", true},
+		{"nocap x = 1; nocap x = 2;", true},
+		{"nocap x = 1; squad x { a };", true},
+		{"nocap x = 1; sigma x {};", true},
+		{"cook() { nocap y = 1; amogus y = 2; };", true},
+		{"amogus x = 1; nocap x = 2;", false},
+		{"nocap x = 1; cook() { amogus x = 2; };", false},
+		{"cook() { nocap y = 1; }; amogus y = 2;", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		p.Parse()
+
+		if (len(p.Errors()) > 0) != tt.fail {
+			t.Errorf("parser errors for %s: got=%v", tt.got, p.Errors())
+		}
+	}
+}
+
+func TestSetLiteral(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{"{1, 2, 3}", "{1, 2, 3}"},
+		{"{1}", "{1}"},
+		{"{a + 1, b,}", "{(a + 1), b}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewLexer(tt.got)
+		p := NewParser(l)
+		program := p.Parse()
+		checkParser(t, p)
+
+		stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.SetLiteral); !ok {
+			t.Errorf("stmt.Expression not *ast.SetLiteral: got=%T", stmt.Expression)
+		}
+		if program.String() != tt.want {
+			t.Errorf("program.String not equal to %s: got=%s", tt.want, program.String())
+		}
+	}
+}
+
+func TestForEachExpression(t *testing.T) {
+	got := "mew (x in xs) { x }"
+	l := lexer.NewLexer(got)
+	p := NewParser(l)
+	program := p.Parse()
+	checkParser(t, p)
+
+	stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.ForEachExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression not *ast.ForEachExpression: got=%T", stmt.Expression)
+	}
+	testIdentifier(t, exp.Variable, "x")
+	testIdentifier(t, exp.Iterable, "xs")
+	if program.String() != "mew(x in xs){x}" {
+		t.Errorf("program.String not equal to %s: got=%s", "mew(x in xs){x}", program.String())
+	}
+
+	// a non identifier on the left keeps the while loop
+	l = lexer.NewLexer("mew (1 in xs) { x }")
+	p = NewParser(l)
+	program = p.Parse()
+	checkParser(t, p)
+
+	stmt, _ = program.Statements[0].(*ast.ExpressionStatement)
+	if _, ok := stmt.Expression.(*ast.ForExpression); !ok {
+		t.Errorf("stmt.Expression not *ast.ForExpression: got=%T", stmt.Expression)
+	}
+}
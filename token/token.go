@@ -5,19 +5,34 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int // 1-based, zero for made up tokens
+	Column  int
 }
 
 // available token types
 const (
-	LET    = "LET"
-	IDENT  = "IDENT"
-	FUNC   = "FUNC"
-	TRUE   = "TRUE"
-	FALSE  = "FALSE"
-	IF     = "IF"
-	ELSE   = "ELSE"
-	RETURN = "RETURN"
-	FOR    = "FOR"
+	LET     = "LET"
+	CONST   = "CONST"
+	IDENT   = "IDENT"
+	FUNC    = "FUNC"
+	TRUE    = "TRUE"
+	FALSE   = "FALSE"
+	IF      = "IF"
+	ELSE    = "ELSE"
+	RETURN  = "RETURN"
+	FOR     = "FOR"
+	RECORD  = "RECORD"
+	CLASS   = "CLASS"
+	THROW   = "THROW"
+	TRY     = "TRY"
+	CATCH   = "CATCH"
+	FINALLY = "FINALLY"
+	IN      = "IN"
+	YIELD   = "YIELD"
+	MACRO   = "MACRO"
+	SPAWN   = "SPAWN"
+	ASYNC   = "ASYNC"
+	AWAIT   = "AWAIT"
 
 	INT    = "INT"
 	STRING = "STRING"
@@ -32,8 +47,20 @@ const (
 	MORE     = ">"
 	EQUAL    = "=="
 	NOTEQUAL = "!="
+	COALESCE = "??"
+	BITAND   = "&"
+	BITOR    = "|"
+	BITXOR   = "^"
+	BITNOT   = "~"
+	LSHIFT   = "<<"
+	RSHIFT   = ">>"
+	PIPE     = "|>"
 
 	COMMA     = ","
+	ELLIPSIS  = "..."
+	ARROW     = "=>"
+	DOT       = "."
+	OPTIONAL  = "?."
 	COLON     = ":"
 	SEMICOLON = ";"
 	LPAREN    = "("
@@ -48,14 +75,27 @@ const (
 )
 
 var keywords = map[string]TokenType{
-	"amogus": LET,
-	"cook":   FUNC,
-	"fax":    TRUE,
-	"cap":    FALSE,
-	"hawk":   IF,
-	"tuah":   ELSE,
-	"rizz":   RETURN,
-	"mew":    FOR,
+	"amogus":  LET,
+	"nocap":   CONST,
+	"cook":    FUNC,
+	"fax":     TRUE,
+	"cap":     FALSE,
+	"hawk":    IF,
+	"tuah":    ELSE,
+	"rizz":    RETURN,
+	"mew":     FOR,
+	"squad":   RECORD,
+	"sigma":   CLASS,
+	"yeet":    THROW,
+	"sus":     TRY,
+	"cope":    CATCH,
+	"periodt": FINALLY,
+	"in":      IN,
+	"slay":    YIELD,
+	"cheat":   MACRO,
+	"sendit":  SPAWN,
+	"lowkey":  ASYNC,
+	"holdup":  AWAIT,
 }
 
 func NewToken(ttype TokenType, char byte) Token {